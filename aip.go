@@ -0,0 +1,160 @@
+package pagination
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidPageToken is returned for page tokens which were not returned by
+// `ListPolicy.PageResponse`
+var ErrInvalidPageToken = &ListError{Code: "INVALID_ARGUMENT", Message: "Invalid page token"}
+
+// ErrPageTokenMismatch is returned for page tokens of requests with other parameters
+var ErrPageTokenMismatch = &ListError{Code: "INVALID_ARGUMENT", Message: "Page token does not match request parameters"}
+
+// ErrNoPageTokenSecret is returned when page tokens are used with a policy
+// without TokenSecret
+var ErrNoPageTokenSecret = errors.New("No page token secret")
+
+// PageRequest is an AIP-158 list request, e.g. of a gRPC service
+type PageRequest struct {
+	PageSize  int32  `json:"pageSize"`
+	PageToken string `json:"pageToken"`
+	// Params are the other parameters of the request (e.g. filter and
+	// order_by), which must not change while paginating
+	Params map[string]interface{} `json:"params"`
+}
+
+// PageResponse is an AIP-158 list response, the next page token being empty
+// on the last page
+type PageResponse struct {
+	Items         []interface{} `json:"items"`
+	NextPageToken string        `json:"nextPageToken"`
+	TotalSize     int           `json:"totalSize"`
+}
+
+// pageToken is the content of page tokens
+type pageToken struct {
+	After  ListCursor `json:"a"`
+	Params string     `json:"p"`
+}
+
+// pageParamsHash identifies the parameters of a request
+func pageParamsHash(params map[string]interface{}) (string, error) {
+	// maps are marshalled with sorted keys
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// signPageToken returns a page token signed with the token secret of the policy
+func (p ListPolicy) signPageToken(token pageToken) (string, error) {
+	if len(p.TokenSecret) == 0 {
+		return "", ErrNoPageTokenSecret
+	}
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, p.TokenSecret)
+	mac.Write(b)
+	return base64.RawURLEncoding.EncodeToString(append(mac.Sum(nil), b...)), nil
+}
+
+// openPageToken returns the content of a page token signed by `signPageToken`
+func (p ListPolicy) openPageToken(value string) (pageToken, error) {
+	token := pageToken{}
+	if len(p.TokenSecret) == 0 {
+		return token, ErrNoPageTokenSecret
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) < sha256.Size {
+		return token, ErrInvalidPageToken
+	}
+	mac := hmac.New(sha256.New, p.TokenSecret)
+	mac.Write(b[sha256.Size:])
+	if !hmac.Equal(mac.Sum(nil), b[:sha256.Size]) {
+		return token, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(b[sha256.Size:], &token); err != nil || token.After == "" {
+		return token, ErrInvalidPageToken
+	}
+	return token, nil
+}
+
+// PageArguments returns the list arguments of an AIP-158 request. Page sizes
+// of 0 are the default page size of the policy, see `Apply`, and page sizes
+// exceeding the maximum page size are clamped to it.
+// Page tokens are signed with the TokenSecret of the policy, so that clients
+// cannot forge them.
+// Errors are `ListError`s with the `INVALID_ARGUMENT` code.
+func (p ListPolicy) PageArguments(req PageRequest) (ListArguments, error) {
+	args := NewListArguments(nil)
+	if req.PageSize < 0 {
+		return args, ErrNegativePageSize
+	}
+	if req.PageToken != "" {
+		token, err := p.openPageToken(req.PageToken)
+		if err != nil {
+			return args, err
+		}
+		params, err := pageParamsHash(req.Params)
+		if err != nil {
+			return args, err
+		}
+		if token.Params != params {
+			return args, ErrPageTokenMismatch
+		}
+		args.After = token.After
+	}
+	if req.PageSize > 0 {
+		args.First = int(req.PageSize)
+		if p.MaxPageSize > 0 && args.First > p.MaxPageSize {
+			args.First = p.MaxPageSize
+		}
+	}
+	return p.Apply(args)
+}
+
+// PageResponse returns the AIP-158 response of a request given the page
+// loaded with the arguments of `PageArguments`
+func (p ListPolicy) PageResponse(req PageRequest, list *List) (*PageResponse, error) {
+	res := &PageResponse{
+		Items:     list.Items,
+		TotalSize: list.TotalCount,
+	}
+	if !list.PageInfo.HasNextPage || list.PageInfo.EndCursor == "" {
+		return res, nil
+	}
+	params, err := pageParamsHash(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	res.NextPageToken, err = p.signPageToken(pageToken{After: list.PageInfo.EndCursor, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListPage loads the page of source requested by an AIP-158 request, see
+// `PageArguments` and `PageResponse`
+func (p ListPolicy) ListPage(source ListSource, req PageRequest, ctx context.Context) (*PageResponse, error) {
+	args, err := p.PageArguments(req)
+	if err != nil {
+		return nil, err
+	}
+	list, err := source.List(args, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.PageResponse(req, list)
+}
@@ -0,0 +1,108 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var aipTestPolicy = pagination.ListPolicy{DefaultPageSize: 2, MaxPageSize: 3, TokenSecret: []byte("secret")}
+
+func TestListPolicy_ListPage_FollowsPageTokens(t *testing.T) {
+	source := pagination.ArrayListSource(arrayListTestLetters)
+	params := map[string]interface{}{"filter": "vowel = false", "order_by": "value"}
+
+	res, err := aipTestPolicy.ListPage(source, pagination.PageRequest{Params: params}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"A", "B"}, res.Items)
+	assert.Equal(t, 5, res.TotalSize)
+	assert.NotEmpty(t, res.NextPageToken)
+
+	// page sizes may change between pages
+	res, err = aipTestPolicy.ListPage(source, pagination.PageRequest{
+		PageSize:  3,
+		PageToken: res.NextPageToken,
+		Params:    map[string]interface{}{"order_by": "value", "filter": "vowel = false"},
+	}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"C", "D", "E"}, res.Items)
+	assert.Empty(t, res.NextPageToken)
+}
+
+func TestListPolicy_PageArguments_ClampsPageSize(t *testing.T) {
+	args, err := aipTestPolicy.PageArguments(pagination.PageRequest{PageSize: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, args.First)
+
+	args, err = aipTestPolicy.PageArguments(pagination.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, args.First)
+
+	args, err = pagination.ListPolicy{}.PageArguments(pagination.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.NewListArguments(nil), args)
+
+	// pages are bounded by the maximum size without default
+	args, err = pagination.ListPolicy{MaxPageSize: 3}.PageArguments(pagination.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, args.First)
+
+	_, err = aipTestPolicy.PageArguments(pagination.PageRequest{PageSize: -1})
+	assert.Equal(t, pagination.ErrNegativePageSize, err)
+}
+
+func TestListPolicy_PageArguments_RejectsChangedParams(t *testing.T) {
+	list := pagination.ListFromArray(arrayListTestLetters, pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}))
+	res, err := aipTestPolicy.PageResponse(pagination.PageRequest{
+		Params: map[string]interface{}{"filter": "vowel = false"},
+	}, list)
+	assert.NoError(t, err)
+
+	args, err := aipTestPolicy.PageArguments(pagination.PageRequest{
+		PageToken: res.NextPageToken,
+		Params:    map[string]interface{}{"filter": "vowel = false"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(1), args.After)
+
+	_, err = aipTestPolicy.PageArguments(pagination.PageRequest{
+		PageToken: res.NextPageToken,
+		Params:    map[string]interface{}{"filter": "vowel = true"},
+	})
+	assert.Equal(t, pagination.ErrPageTokenMismatch, err)
+
+	_, err = aipTestPolicy.PageArguments(pagination.PageRequest{PageToken: res.NextPageToken})
+	assert.Equal(t, pagination.ErrPageTokenMismatch, err)
+}
+
+func TestListPolicy_PageArguments_RejectsInvalidTokens(t *testing.T) {
+	for _, token := range []string{"not a token", string(pagination.OffsetToCursor(1)), "e30"} {
+		_, err := aipTestPolicy.PageArguments(pagination.PageRequest{PageToken: token})
+		assert.Equal(t, pagination.ErrInvalidPageToken, err, token)
+	}
+	assert.Equal(t, map[string]interface{}{"code": "INVALID_ARGUMENT"}, pagination.ErrInvalidPageToken.Extensions())
+}
+
+func TestListPolicy_PageArguments_RejectsForgedTokens(t *testing.T) {
+	list := pagination.ListFromArray(arrayListTestLetters, pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}))
+	forger := aipTestPolicy
+	forger.TokenSecret = []byte("guess")
+	res, err := forger.PageResponse(pagination.PageRequest{}, list)
+	assert.NoError(t, err)
+	_, err = aipTestPolicy.PageArguments(pagination.PageRequest{PageToken: res.NextPageToken})
+	assert.Equal(t, pagination.ErrInvalidPageToken, err)
+
+	// tokens need a secret
+	unsigned := aipTestPolicy
+	unsigned.TokenSecret = nil
+	_, err = unsigned.PageResponse(pagination.PageRequest{}, list)
+	assert.Equal(t, pagination.ErrNoPageTokenSecret, err)
+	_, err = unsigned.PageArguments(pagination.PageRequest{PageToken: res.NextPageToken})
+	assert.Equal(t, pagination.ErrNoPageTokenSecret, err)
+}
@@ -132,10 +132,11 @@ func CursorToOffset(cursor ListCursor) (int, error) {
 }
 
 // CursorForObjectInList returns the cursor associated with an object in an array.
+// Objects are compared by content, see `CursorForObjectInListByKey` and
+// `ListIndex` to compare identity keys.
 func CursorForObjectInList(data []interface{}, object interface{}) ListCursor {
 	offset := -1
 	for i, d := range data {
-		// TODO: better object comparison
 		if reflect.DeepEqual(d, object) {
 			offset = i
 			break
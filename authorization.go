@@ -0,0 +1,77 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ErrNotAuthorized is returned when an authorization policy denies access to an object
+var ErrNotAuthorized = errors.New("Not authorized")
+
+// AuthorizeFn tells whether the viewer carried by ctx may access the object.
+// A non-nil error aborts the resolution instead of denying access.
+type AuthorizeFn func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error)
+
+// AuthorizationPolicies maps GraphQL type names to the policy guarding their objects
+type AuthorizationPolicies map[string]AuthorizeFn
+
+// Authorize evaluates the policy registered for typeName against obj.
+// Objects whose type has no registered policy are allowed.
+func (policies AuthorizationPolicies) Authorize(typeName string, obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+	authorize, ok := policies[typeName]
+	if !ok || authorize == nil {
+		return true, nil
+	}
+	return authorize(obj, info, ctx)
+}
+
+// AuthorizedItems returns, in order, the items of data the viewer is allowed to access.
+// Apply it before building the list window (i.e. before `ListFromArray`), so that
+// cursors and totalCount only account for visible items.
+func AuthorizedItems(data []interface{}, authorize AuthorizeFn, info graphql.ResolveInfo, ctx context.Context) ([]interface{}, error) {
+	if authorize == nil {
+		return data, nil
+	}
+	items := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		if err := ContextError(ctx); err != nil {
+			return nil, err
+		}
+		ok, err := authorize(item, info, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// authorizeItem evaluates the per-type policies against an object fetched by
+// the `Item` root field. Objects whose concrete type can't be determined are denied.
+func authorizeItem(policies AuthorizationPolicies, itemInterface *graphql.Interface, typeResolve graphql.ResolveTypeFn, obj interface{}, info graphql.ResolveInfo, ctx context.Context) error {
+	var objectType *graphql.Object
+	if typeResolve != nil {
+		objectType = typeResolve(graphql.ResolveTypeParams{
+			Value:   obj,
+			Info:    info,
+			Context: ctx,
+		})
+	} else {
+		objectType = possibleTypeOf(info.Schema.PossibleTypes(itemInterface), obj, info, ctx)
+	}
+	if objectType == nil {
+		return ErrNotAuthorized
+	}
+	ok, err := policies.Authorize(objectType.Name(), obj, info, ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotAuthorized
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package pagination_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type authTestViewerKey struct{}
+
+func authTestViewer(ctx context.Context) string {
+	viewer, _ := ctx.Value(authTestViewerKey{}).(string)
+	return viewer
+}
+
+var authTestUserData = map[string]*user{
+	"1": &user{1, "John Doe"},
+	"2": &user{2, "Jane Smith"},
+}
+var authTestPhotoData = map[string]*photo{
+	"3": &photo{3, 300},
+	"4": &photo{4, 400},
+}
+
+var authTestUserType *graphql.Object
+var authTestPhotoType *graphql.Object
+var authTestSchema graphql.Schema
+
+// photos are only visible to admins, users are visible to everyone
+var authTestPhotoPolicy pagination.AuthorizeFn = func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+	return authTestViewer(ctx) == "admin", nil
+}
+
+func init() {
+	itemDef := pagination.NewItemDefinitions(pagination.ItemDefinitionsConfig{
+		IDFetcher: func(id string, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+			if user, ok := authTestUserData[id]; ok {
+				return user, nil
+			}
+			if photo, ok := authTestPhotoData[id]; ok {
+				return photo, nil
+			}
+			return nil, errors.New("Unknown Item")
+		},
+		TypeResolve: func(p graphql.ResolveTypeParams) *graphql.Object {
+			switch p.Value.(type) {
+			case *user:
+				return authTestUserType
+			case *photo:
+				return authTestPhotoType
+			default:
+				panic(fmt.Sprintf("Unknown object type `%v`", p.Value))
+			}
+		},
+		Authorize: pagination.AuthorizationPolicies{
+			"Photo": authTestPhotoPolicy,
+		},
+	})
+	authTestUserType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		Interfaces: []*graphql.Interface{itemDef.ItemInterface},
+	})
+	authTestPhotoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Photo",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"width": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+		Interfaces: []*graphql.Interface{itemDef.ItemInterface},
+	})
+
+	photoListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:      "Photo",
+		ItemType:  authTestPhotoType,
+		Authorize: authTestPhotoPolicy,
+	})
+	userListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "User",
+		ItemType: authTestUserType,
+		// viewers only see themselves
+		Authorize: func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+			return obj.(*user).Name == authTestViewer(ctx), nil
+		},
+	})
+
+	var err error
+	authTestSchema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"Item": itemDef.ItemField,
+				"photos": &graphql.Field{
+					Type: photoListDef.ListType,
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						photos, err := photoListDef.AuthorizedItems([]interface{}{
+							authTestPhotoData["3"],
+							authTestPhotoData["4"],
+						}, p.Info, p.Context)
+						if err != nil {
+							return nil, err
+						}
+						return pagination.ListFromArray(photos, pagination.NewListArguments(p.Args)), nil
+					},
+				},
+				"users": &graphql.Field{
+					Type: userListDef.ListType,
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						users, err := userListDef.AuthorizedItems([]interface{}{
+							authTestUserData["1"],
+							authTestUserData["2"],
+						}, p.Info, p.Context)
+						if err != nil {
+							return nil, err
+						}
+						return pagination.ListFromArray(users, pagination.NewListArguments(p.Args)), nil
+					},
+				},
+			},
+		}),
+		Types: []graphql.Type{authTestUserType, authTestPhotoType},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func authTestDo(viewer string, query string) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:        authTestSchema,
+		RequestString: query,
+		Context:       context.WithValue(context.Background(), authTestViewerKey{}, viewer),
+	})
+}
+
+func TestAuthorization_Item_AllowsTypesWithoutPolicy(t *testing.T) {
+	result := authTestDo("guest", `{ Item(id: "1") { id } }`)
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"Item": map[string]interface{}{"id": "1"},
+	}, result.Data)
+}
+
+func TestAuthorization_Item_AllowsAuthorizedViewer(t *testing.T) {
+	result := authTestDo("admin", `{ Item(id: "4") { id ... on Photo { width } } }`)
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"Item": map[string]interface{}{"id": "4", "width": 400},
+	}, result.Data)
+}
+
+func TestAuthorization_Item_ReturnsNullForDeniedObjects(t *testing.T) {
+	result := authTestDo("guest", `{ Item(id: "4") { id } }`)
+	assert.EqualValues(t, map[string]interface{}{"Item": nil}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, pagination.ErrNotAuthorized.Error(), result.Errors[0].Message)
+	}
+}
+
+func TestAuthorization_List_FiltersBeforeCountingItems(t *testing.T) {
+	result := authTestDo("Jane Smith", `{
+        users(first: 1) {
+          totalCount
+          items { name }
+          pageInfo { startCursor hasNextPage }
+        }
+        photos { totalCount }
+      }`)
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"users": map[string]interface{}{
+			"totalCount": 1,
+			"items": []interface{}{
+				map[string]interface{}{"name": "Jane Smith"},
+			},
+			"pageInfo": map[string]interface{}{
+				"startCursor": "YXJyYXljb25uZWN0aW9uOjA=",
+				"hasNextPage": false,
+			},
+		},
+		"photos": map[string]interface{}{
+			"totalCount": 0,
+		},
+	}, result.Data)
+}
+
+func TestAuthorizedItems_PropagatesPolicyErrors(t *testing.T) {
+	policyErr := errors.New("policy backend unavailable")
+	_, err := pagination.AuthorizedItems(
+		[]interface{}{"A", "B"},
+		func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+			return false, policyErr
+		},
+		graphql.ResolveInfo{},
+		context.Background(),
+	)
+	assert.Equal(t, policyErr, err)
+}
+
+func TestAuthorizedItems_KeepsAllItemsWithoutPolicy(t *testing.T) {
+	items, err := pagination.AuthorizedItems(arrayListTestLetters, nil, graphql.ResolveInfo{}, context.Background())
+	assert.NoError(t, err)
+	assert.EqualValues(t, arrayListTestLetters, items)
+}
+
+func TestListDefinitions_LoadAuthorizesItemsBeforePaginating(t *testing.T) {
+	letterListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "AuthLetter",
+		ItemType: graphql.String,
+		Authorize: func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+			return obj != "B", nil
+		},
+	})
+	p := graphql.ResolveParams{
+		Args:    map[string]interface{}{"first": 2},
+		Context: context.Background(),
+	}
+	res, err := letterListDef.Load(pagination.ArrayListSource(arrayListTestLetters), p)
+	assert.NoError(t, err)
+	list := res.(*pagination.List)
+	assert.Equal(t, []interface{}{"A", "C"}, list.Items)
+	assert.Equal(t, 4, list.TotalCount)
+	assert.Equal(t, pagination.OffsetToCursor(1), list.PageInfo.EndCursor)
+
+	// other sources have to authorize their items
+	_, err = letterListDef.Load(pagination.NewSnapshotList(arrayListTestLetters, 0), p)
+	assert.EqualError(t, err, "Cannot authorize the items of *pagination.SnapshotList")
+}
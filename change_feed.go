@@ -0,0 +1,283 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+const changeFeedPrefix = "changefeed:"
+
+// ErrSyncTokenExpired is returned when the changes following a sync token were
+// pruned, so that the client has to sync the whole list again
+var ErrSyncTokenExpired = errors.New("Sync token expired")
+
+// ErrBackwardChangeFeed is returned when a change feed is paginated backwards
+var ErrBackwardChangeFeed = errors.New("Change feeds can only be paginated forwards")
+
+// Change is the latest modification of a list item. Deleted items are
+// reported as tombstones, i.e. changes without item.
+type Change struct {
+	Seq     int64       `json:"seq"`
+	ID      string      `json:"id"`
+	Item    interface{} `json:"item"`
+	Deleted bool        `json:"deleted"`
+}
+
+// ChangeListType is a page of changes of the list, see `ChangeFeed`. It is
+// built on first use.
+func (d *GraphQLListDefinitions) ChangeListType() *graphql.Object {
+	return d.lazyType("ChangeList", func(name string, itemType graphql.Output) *graphql.Object {
+		changeType := graphql.NewObject(graphql.ObjectConfig{
+			Name:        name + "Change",
+			Description: "The latest change of an item of the list.",
+
+			Fields: graphql.Fields{
+				"id": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.ID),
+					Description: "The id of the changed item.",
+				},
+				"deleted": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Boolean),
+					Description: "Whether the item was deleted.",
+				},
+				"item": &graphql.Field{
+					Type:        itemType,
+					Description: "The updated item, null when deleted.",
+				},
+			},
+		})
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        name + "ChangeList",
+			Description: "changes of the list since a sync token.",
+
+			Fields: graphql.Fields{
+				"items": &graphql.Field{
+					Type:        graphql.NewList(changeType),
+					Description: "Changes of the list.",
+				},
+				"pageInfo": &graphql.Field{
+					Type:        graphql.NewNonNull(pageInfoType),
+					Description: "Information to aid in pagination, the end cursor being the next sync token.",
+				},
+				"totalCount": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Count of all changes since the sync token.",
+				},
+			},
+		})
+	})
+}
+
+// ChangeSource is a list source recording item modifications with increasing
+// modification sequence numbers
+type ChangeSource interface {
+	// ChangesSince returns, in sequence order, at most limit (all if negative)
+	// latest changes of items modified after seq, along with the number of
+	// remaining changes. When ctx is done, it may return the changes loaded so
+	// far along with the error.
+	ChangesSince(seq int64, limit int, ctx context.Context) ([]*Change, int, error)
+}
+
+// SeqToCursor creates the sync token from a modification sequence number
+func SeqToCursor(seq int64) ListCursor {
+	str := fmt.Sprintf("%v%v", changeFeedPrefix, seq)
+	return ListCursor(base64.StdEncoding.EncodeToString([]byte(str)))
+}
+
+// CursorToSeq re-derives the modification sequence number from a sync token
+func CursorToSeq(cursor ListCursor) (int64, error) {
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), changeFeedPrefix) {
+		return 0, errors.New("Invalid sync token")
+	}
+	seq, err := strconv.ParseInt(strings.TrimPrefix(string(b), changeFeedPrefix), 10, 64)
+	if err != nil {
+		return 0, errors.New("Invalid sync token")
+	}
+	return seq, nil
+}
+
+// ChangeFeed returns the changes of source since the sync token given as
+// `after` (since the beginning if omitted), at most `first` of them.
+// Items are `*Change`s, the end cursor is the sync token to use for the next
+// request, and `hasNextPage` is false once the client has caught up.
+// totalCount is the number of changes since the sync token.
+func ChangeFeed(source ChangeSource, args ListArguments, ctx context.Context) (*List, error) {
+	if args.Before != "" || args.Last != -1 {
+		return nil, ErrBackwardChangeFeed
+	}
+	seq := int64(0)
+	if args.After != "" {
+		var err error
+		if seq, err = CursorToSeq(args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, remaining, err := source.ChangesSince(seq, args.First, ctx)
+	if err != nil && changes == nil {
+		return nil, err
+	}
+
+	list := NewList()
+	for _, change := range changes {
+		list.Items = append(list.Items, change)
+	}
+	endSeq := seq
+	if len(changes) > 0 {
+		list.PageInfo.StartCursor = SeqToCursor(changes[0].Seq)
+		endSeq = changes[len(changes)-1].Seq
+	}
+	list.PageInfo.EndCursor = SeqToCursor(endSeq)
+	list.PageInfo.HasNextPage = remaining > 0
+	list.TotalCount = len(changes) + remaining
+	if err != nil {
+		// the changes loaded so far can be synced, the client continues from the sync token
+		list.PageInfo.HasNextPage = true
+		return list, err
+	}
+	return list, nil
+}
+
+// ChangeLog is an in-memory ChangeSource. It is also a ListSource of the
+// current items, ordered by last modification. It is safe for concurrent use.
+type ChangeLog struct {
+	mu sync.RWMutex
+	// log holds changes in sequence order, including superseded ones
+	log        []*Change
+	latest     map[string]*Change
+	seq        int64
+	prunedSeq  int64
+	superseded int
+}
+
+// NewChangeLog is a ChangeLog constructor
+func NewChangeLog() *ChangeLog {
+	return &ChangeLog{
+		latest: map[string]*Change{},
+	}
+}
+
+// Put records the creation or update of the item with the given id, and
+// returns its modification sequence number
+func (l *ChangeLog) Put(id string, item interface{}) int64 {
+	return l.record(&Change{ID: id, Item: item})
+}
+
+// Delete records the deletion of the item with the given id, and returns its
+// modification sequence number
+func (l *ChangeLog) Delete(id string) int64 {
+	return l.record(&Change{ID: id, Deleted: true})
+}
+
+func (l *ChangeLog) record(change *Change) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	change.Seq = l.seq
+	if _, ok := l.latest[change.ID]; ok {
+		l.superseded++
+	}
+	l.latest[change.ID] = change
+	l.log = append(l.log, change)
+	if l.superseded > len(l.log)/2 {
+		l.compact()
+	}
+	return change.Seq
+}
+
+// compact drops superseded changes from the log
+func (l *ChangeLog) compact() {
+	log := make([]*Change, 0, len(l.latest))
+	for _, change := range l.log {
+		if l.latest[change.ID] == change {
+			log = append(log, change)
+		}
+	}
+	l.log = log
+	l.superseded = 0
+}
+
+// PruneTombstones forgets the deletions recorded up to seq. Clients whose sync
+// token is older than seq get `ErrSyncTokenExpired` and have to sync again.
+func (l *ChangeLog) PruneTombstones(seq int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, change := range l.latest {
+		if change.Deleted && change.Seq <= seq {
+			delete(l.latest, id)
+			l.superseded++
+		}
+	}
+	if seq > l.prunedSeq {
+		l.prunedSeq = seq
+	}
+	l.compact()
+}
+
+// Seq returns the last modification sequence number
+func (l *ChangeLog) Seq() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.seq
+}
+
+// ChangesSince implements ChangeSource
+func (l *ChangeLog) ChangesSince(seq int64, limit int, ctx context.Context) ([]*Change, int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if seq < l.prunedSeq && seq != 0 {
+		return nil, 0, ErrSyncTokenExpired
+	}
+	start := sort.Search(len(l.log), func(i int) bool {
+		return l.log[i].Seq > seq
+	})
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	changes := []*Change{}
+	remaining := 0
+	for _, change := range l.log[start:] {
+		if isDone(done) {
+			return changes, 0, ContextError(ctx)
+		}
+		if l.latest[change.ID] != change {
+			continue
+		}
+		// a full sync doesn't need tombstones
+		if seq == 0 && change.Deleted {
+			continue
+		}
+		if limit >= 0 && len(changes) >= limit {
+			remaining++
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, remaining, nil
+}
+
+// List implements ListSource
+func (l *ChangeLog) List(args ListArguments, ctx context.Context) (*List, error) {
+	if err := ContextError(ctx); err != nil {
+		return nil, err
+	}
+	l.mu.RLock()
+	items := []interface{}{}
+	for _, change := range l.log {
+		if l.latest[change.ID] == change && !change.Deleted {
+			items = append(items, change.Item)
+		}
+	}
+	l.mu.RUnlock()
+	return ListFromArray(items, args), nil
+}
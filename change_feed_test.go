@@ -0,0 +1,170 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeqToCursor_RoundTrips(t *testing.T) {
+	seq, err := pagination.CursorToSeq(pagination.SeqToCursor(42))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), seq)
+
+	_, err = pagination.CursorToSeq(pagination.OffsetToCursor(42))
+	assert.EqualError(t, err, "Invalid sync token")
+}
+
+func TestChangeFeed_SyncsChangesSinceToken(t *testing.T) {
+	log := pagination.NewChangeLog()
+	log.Put("1", "A")
+	log.Put("2", "B")
+	log.Put("3", "C")
+	log.Delete("2")
+
+	// full sync, without tombstones
+	full, err := pagination.ChangeFeed(log, pagination.NewListArguments(nil), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&pagination.Change{Seq: 1, ID: "1", Item: "A"},
+		&pagination.Change{Seq: 3, ID: "3", Item: "C"},
+	}, full.Items)
+	assert.False(t, full.PageInfo.HasNextPage)
+	assert.Equal(t, pagination.SeqToCursor(3), full.PageInfo.EndCursor)
+
+	log.Put("1", "A2")
+	log.Put("4", "D")
+	log.Delete("3")
+
+	// delta sync, paginated
+	args := pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(full.PageInfo.EndCursor),
+	})
+	page, err := pagination.ChangeFeed(log, args, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&pagination.Change{Seq: 4, ID: "2", Deleted: true},
+		&pagination.Change{Seq: 5, ID: "1", Item: "A2"},
+	}, page.Items)
+	assert.True(t, page.PageInfo.HasNextPage)
+	assert.Equal(t, 4, page.TotalCount)
+
+	args.After = page.PageInfo.EndCursor
+	page, err = pagination.ChangeFeed(log, args, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&pagination.Change{Seq: 6, ID: "4", Item: "D"},
+		&pagination.Change{Seq: 7, ID: "3", Deleted: true},
+	}, page.Items)
+	assert.False(t, page.PageInfo.HasNextPage)
+
+	// caught up, the sync token is kept
+	args.After = page.PageInfo.EndCursor
+	page, err = pagination.ChangeFeed(log, args, context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.False(t, page.PageInfo.HasNextPage)
+	assert.Equal(t, pagination.SeqToCursor(7), page.PageInfo.EndCursor)
+}
+
+func TestChangeFeed_RejectsBackwardPagination(t *testing.T) {
+	args := pagination.NewListArguments(map[string]interface{}{"last": 2})
+	_, err := pagination.ChangeFeed(pagination.NewChangeLog(), args, context.Background())
+	assert.Equal(t, pagination.ErrBackwardChangeFeed, err)
+}
+
+func TestChangeLog_PruneTombstones_ExpiresOlderTokens(t *testing.T) {
+	log := pagination.NewChangeLog()
+	log.Put("1", "A")
+	token := pagination.SeqToCursor(log.Seq())
+	log.Delete("1")
+	log.PruneTombstones(log.Seq())
+
+	args := pagination.NewListArguments(map[string]interface{}{"after": string(token)})
+	_, err := pagination.ChangeFeed(log, args, context.Background())
+	assert.Equal(t, pagination.ErrSyncTokenExpired, err)
+
+	// a full sync still works
+	list, err := pagination.ChangeFeed(log, pagination.NewListArguments(nil), context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestChangeLog_ListsCurrentItems(t *testing.T) {
+	log := pagination.NewChangeLog()
+	for i, letter := range arrayListTestLetters {
+		log.Put(letter.(string), letter)
+		if i%2 == 1 {
+			log.Delete(letter.(string))
+		}
+	}
+	list, err := log.List(pagination.NewListArguments(nil), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"A", "C", "E"}, list.Items)
+}
+
+func TestChangeFeed_GraphQLChangeListType(t *testing.T) {
+	log := pagination.NewChangeLog()
+	log.Put("1", &user{ID: 1, Name: "Dan"})
+	log.Put("2", &user{ID: 2, Name: "Nick"})
+	after := pagination.SeqToCursor(log.Seq())
+	log.Delete("1")
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	userListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "User",
+		ItemType: userType,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"userChanges": &graphql.Field{
+					Type: userListDef.ChangeListType(),
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return pagination.ChangeFeed(log, pagination.NewListArguments(p.Args), p.Context)
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `query Sync($after: String) {
+          userChanges(after: $after) {
+            items { id deleted item { name } }
+            pageInfo { endCursor hasNextPage }
+            totalCount
+          }
+        }`,
+		VariableValues: map[string]interface{}{"after": string(after)},
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"userChanges": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": "1", "deleted": true, "item": nil},
+			},
+			"pageInfo": map[string]interface{}{
+				"endCursor":   string(pagination.SeqToCursor(3)),
+				"hasNextPage": false,
+			},
+			"totalCount": 1,
+		},
+	}, result.Data)
+}
@@ -0,0 +1,308 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// FieldCostFn returns the cost of a field given its arguments and the cost of
+// its selection set
+type FieldCostFn func(args map[string]interface{}, childrenCost int) int
+
+// CostConfig is the configuration of the query cost analysis.
+// Each field costs 1 plus the cost of its selection set, multiplied by the
+// page size for list fields, i.e. fields taking `ListArgs` or returning the
+// list type of one of Lists. The page size is the first or last argument,
+// or the default of the list policy, or DefaultListSize. Lists without page
+// size, i.e. without limit if DefaultListSize is 0, exceed any budget.
+type CostConfig struct {
+	// Budget is the maximum cost of a query, unlimited if 0
+	Budget int
+	// Lists are the list definitions whose policies apply to their fields
+	Lists []*GraphQLListDefinitions
+	// DefaultListSize is the page size of lists without limit, such lists
+	// being rejected if 0
+	DefaultListSize int
+	// Fields overrides the cost of fields, keyed by "Type.field"
+	Fields map[string]FieldCostFn
+}
+
+// ErrQueryTooComplex returns the error of a query whose cost exceeds the budget
+func ErrQueryTooComplex(cost, budget int) *ListError {
+	return &ListError{
+		Code:    "QUERY_TOO_COMPLEX",
+		Message: fmt.Sprintf("Query cost %v exceeds budget %v", cost, budget),
+	}
+}
+
+type costAnalysis struct {
+	config    CostConfig
+	schema    graphql.Schema
+	policies  map[string]ListPolicy
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// QueryCost returns the estimated cost of the operation of a query document.
+// It doesn't validate the document, invalid selections cost nothing.
+func QueryCost(schema graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}, config CostConfig) (int, error) {
+	a := &costAnalysis{
+		config:    config,
+		schema:    schema,
+		policies:  map[string]ListPolicy{},
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: map[string]interface{}{},
+	}
+	for _, list := range config.Lists {
+		a.policies[list.ListType.Name()] = list.Policy
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return 0, fmt.Errorf("Unknown operation %v", operationName)
+	}
+
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			a.variables[definition.Variable.Name.Value] = a.value(definition.DefaultValue)
+		}
+	}
+	for name, value := range variables {
+		a.variables[name] = value
+	}
+
+	var rootType *graphql.Object
+	switch operation.Operation {
+	case ast.OperationTypeMutation:
+		rootType = schema.MutationType()
+	case ast.OperationTypeSubscription:
+		rootType = schema.SubscriptionType()
+	default:
+		rootType = schema.QueryType()
+	}
+	if rootType == nil {
+		return 0, fmt.Errorf("Schema has no %v type", operation.Operation)
+	}
+	return a.selectionSetCost(rootType, operation.SelectionSet, map[string]bool{}), nil
+}
+
+func (a *costAnalysis) selectionSetCost(parentType graphql.Type, selectionSet *ast.SelectionSet, visited map[string]bool) int {
+	if selectionSet == nil {
+		return 0
+	}
+	var fields graphql.FieldDefinitionMap
+	switch t := parentType.(type) {
+	case *graphql.Object:
+		fields = t.Fields()
+	case *graphql.Interface:
+		fields = t.Fields()
+	}
+
+	cost := 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if field, ok := fields[selection.Name.Value]; ok {
+				cost = addCost(cost, a.fieldCost(parentType, field, selection, visited))
+			}
+		case *ast.InlineFragment:
+			// selections on each possible type are all counted
+			fragmentType := parentType
+			if selection.TypeCondition != nil {
+				fragmentType = a.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			cost = addCost(cost, a.selectionSetCost(fragmentType, selection.SelectionSet, visited))
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := a.fragments[name]
+			if !ok || visited[name] {
+				continue
+			}
+			visited[name] = true
+			cost = addCost(cost, a.selectionSetCost(a.schema.Type(fragment.TypeCondition.Name.Value), fragment.SelectionSet, visited))
+			delete(visited, name)
+		}
+	}
+	return cost
+}
+
+func (a *costAnalysis) fieldCost(parentType graphql.Type, field *graphql.FieldDefinition, selection *ast.Field, visited map[string]bool) int {
+	args := map[string]interface{}{}
+	for _, arg := range selection.Arguments {
+		args[arg.Name.Value] = a.value(arg.Value)
+	}
+	fieldType, ok := graphql.GetNamed(field.Type).(graphql.Type)
+	if !ok {
+		return 1
+	}
+	childrenCost := a.selectionSetCost(fieldType, selection.SelectionSet, visited)
+
+	if costFn, ok := a.config.Fields[parentType.Name()+"."+field.Name]; ok {
+		if cost := costFn(args, childrenCost); cost > 0 {
+			return cost
+		}
+		return 0
+	}
+
+	policy, isList := a.policies[fieldType.Name()]
+	for _, arg := range field.Args {
+		if arg.Name() == "first" || arg.Name() == "last" {
+			isList = true
+		}
+	}
+	if !isList {
+		return addCost(1, childrenCost)
+	}
+	size := policy.PageSize(NewListArguments(args))
+	if size == -1 {
+		if a.config.DefaultListSize <= 0 {
+			// unbounded lists exceed any budget
+			return math.MaxInt
+		}
+		size = a.config.DefaultListSize
+	}
+	// negative sizes are rejected by the list policy, and must not lower the cost
+	if size < 0 {
+		size = 0
+	}
+	return addCost(1, mulCost(size, childrenCost))
+}
+
+// addCost adds non-negative costs, saturating instead of overflowing
+func addCost(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// mulCost multiplies non-negative costs, saturating instead of overflowing
+func mulCost(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// value returns the value of an argument, replacing variables
+func (a *costAnalysis) value(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return a.variables[value.Name.Value]
+	case *ast.IntValue:
+		i, _ := strconv.Atoi(value.Value)
+		return i
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(value.Value, 64)
+		return f
+	case *ast.ListValue:
+		values := make([]interface{}, len(value.Values))
+		for i, v := range value.Values {
+			values[i] = a.value(v)
+		}
+		return values
+	case *ast.ObjectValue:
+		fields := map[string]interface{}{}
+		for _, field := range value.Fields {
+			fields[field.Name.Value] = a.value(field.Value)
+		}
+		return fields
+	case nil:
+		return nil
+	}
+	return value.GetValue()
+}
+
+type costContextKey struct{}
+
+// CostAnalysis is a GraphQL extension rejecting queries whose cost exceeds the
+// budget before execution, and reporting the cost of the others in the
+// `cost` response extension, see `QueryCost`.
+// graphql-go reports errors of extensions prefixed with the extension hook.
+type CostAnalysis struct {
+	Config CostConfig
+}
+
+// NewCostAnalysis is a CostAnalysis constructor
+func NewCostAnalysis(config CostConfig) *CostAnalysis {
+	return &CostAnalysis{Config: config}
+}
+
+// Init implements graphql.Extension
+func (c *CostAnalysis) Init(ctx context.Context, p *graphql.Params) context.Context {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(p.RequestString),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		// parse errors are reported by graphql
+		return ctx
+	}
+	cost, err := QueryCost(p.Schema, document, p.OperationName, p.VariableValues, c.Config)
+	if err != nil {
+		return ctx
+	}
+	if c.Config.Budget > 0 && cost > c.Config.Budget {
+		panic(ErrQueryTooComplex(cost, c.Config.Budget))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, costContextKey{}, cost)
+}
+
+// Name implements graphql.Extension
+func (c *CostAnalysis) Name() string {
+	return "cost"
+}
+
+// ParseDidStart implements graphql.Extension
+func (c *CostAnalysis) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+// ValidationDidStart implements graphql.Extension
+func (c *CostAnalysis) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+// ExecutionDidStart implements graphql.Extension
+func (c *CostAnalysis) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(*graphql.Result) {}
+}
+
+// ResolveFieldDidStart implements graphql.Extension
+func (c *CostAnalysis) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	return ctx, func(interface{}, error) {}
+}
+
+// HasResult implements graphql.Extension
+func (c *CostAnalysis) HasResult() bool {
+	return true
+}
+
+// GetResult implements graphql.Extension
+func (c *CostAnalysis) GetResult(ctx context.Context) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Value(costContextKey{})
+}
@@ -0,0 +1,242 @@
+package pagination_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var costTestPilotListDef = pagination.ListDefinitions(pagination.ListConfig{
+	Name: "CostPilot",
+	ItemType: graphql.NewObject(graphql.ObjectConfig{
+		Name: "CostPilot",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	}),
+})
+
+var costTestShipListDef = pagination.ListDefinitions(pagination.ListConfig{
+	Name: "CostShip",
+	ItemType: graphql.NewObject(graphql.ObjectConfig{
+		Name: "CostShip",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"pilots": &graphql.Field{
+				Type: costTestPilotListDef.ListType,
+				Args: pagination.ListArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return pagination.ListFromArray([]interface{}{map[string]interface{}{"name": "Luke"}}, pagination.NewListArguments(p.Args)), nil
+				},
+			},
+		},
+	}),
+	Policy: pagination.ListPolicy{DefaultPageSize: 10, MaxPageSize: 50},
+})
+
+var costTestFactionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CostFaction",
+	Fields: graphql.Fields{
+		"name": &graphql.Field{
+			Type: graphql.String,
+		},
+		"ships": &graphql.Field{
+			Type: costTestShipListDef.ListType,
+			Args: pagination.ListArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				args, err := costTestShipListDef.ListArguments(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return pagination.ListFromArray([]interface{}{map[string]interface{}{"name": "X-Wing"}}, args), nil
+			},
+		},
+	},
+})
+
+func newCostTestSchema(extensions ...graphql.Extension) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"factions": &graphql.Field{
+					Type: graphql.NewList(costTestFactionType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return []interface{}{map[string]interface{}{"name": "Rebels"}}, nil
+					},
+				},
+			},
+		}),
+		Extensions: extensions,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+const costTestQuery = `query Factions($pilots: Int = 10) {
+  factions {
+    name
+    ships(first: 50) {
+      items { ...shipFields }
+    }
+  }
+}
+
+fragment shipFields on CostShip {
+  name
+  pilots(first: $pilots) { items { name } totalCount }
+}`
+
+func TestQueryCost_MultipliesListsByPageSize(t *testing.T) {
+	document, err := parser.Parse(parser.ParseParams{Source: costTestQuery})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pagination.CostConfig{
+		Lists: []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	}
+
+	// pilots: 1 + 10 * (items: 1 + name: 1, totalCount: 1) = 31
+	// ships: 1 + 50 * (items: 1 + name: 1 + pilots: 31) = 1651
+	// factions: 1 + name: 1 + ships: 1651 = 1653
+	cost, err := pagination.QueryCost(newCostTestSchema(), document, "", nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1653, cost)
+
+	cost, err = pagination.QueryCost(newCostTestSchema(), document, "Factions", map[string]interface{}{"pilots": 1}, config)
+	assert.NoError(t, err)
+	// pilots: 1 + 1 * 3 = 4, ships: 1 + 50 * 6 = 301
+	assert.Equal(t, 303, cost)
+
+	// the name of factions is expensive
+	config.Fields = map[string]pagination.FieldCostFn{
+		"CostFaction.name": func(args map[string]interface{}, childrenCost int) int {
+			return 100
+		},
+	}
+	cost, err = pagination.QueryCost(newCostTestSchema(), document, "", nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1752, cost)
+
+	_, err = pagination.QueryCost(newCostTestSchema(), document, "Ships", nil, config)
+	assert.EqualError(t, err, "Unknown operation Ships")
+}
+
+func TestQueryCost_UsesPolicyDefaultsAndDefaultListSize(t *testing.T) {
+	document, err := parser.Parse(parser.ParseParams{Source: `{
+      factions { ships { items { pilots { totalCount } } } }
+    }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err := pagination.QueryCost(newCostTestSchema(), document, "", nil, pagination.CostConfig{
+		Lists:           []*pagination.GraphQLListDefinitions{costTestShipListDef},
+		DefaultListSize: 5,
+	})
+	assert.NoError(t, err)
+	// pilots: 1 + 5 * 1 = 6, ships: 1 + 10 * (1 + 6) = 71
+	assert.Equal(t, 72, cost)
+
+	// page sizes are capped by the list policy
+	document, err = parser.Parse(parser.ParseParams{Source: `{ factions { ships(first: 100) { totalCount } } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err = pagination.QueryCost(newCostTestSchema(), document, "", nil, pagination.CostConfig{
+		Lists: []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1+1+50, cost)
+}
+
+func TestQueryCost_ClampsNegativeAndHugePageSizes(t *testing.T) {
+	config := pagination.CostConfig{
+		Lists: []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	}
+	cost := func(query string) int {
+		document, err := parser.Parse(parser.ParseParams{Source: query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cost, err := pagination.QueryCost(newCostTestSchema(), document, "", nil, config)
+		assert.NoError(t, err)
+		return cost
+	}
+
+	// pilots: 1 + 0 * 1 = 1, ships: 1 + 2 * (items: 1 + 1) = 5, factions: 1 + 5
+	assert.Equal(t, 6, cost(`{ factions { ships(first: 2) { items { pilots(first: -100000) { totalCount } } } } }`))
+
+	// costs saturate instead of overflowing
+	config.Fields = map[string]pagination.FieldCostFn{
+		"CostShip.name": func(args map[string]interface{}, childrenCost int) int {
+			return math.MaxInt / 2
+		},
+	}
+	assert.Equal(t, math.MaxInt, cost(`{ factions { ships { items { name } } } }`))
+}
+
+func TestCostAnalysis_RejectsUnboundedLists(t *testing.T) {
+	schema := newCostTestSchema(pagination.NewCostAnalysis(pagination.CostConfig{
+		Budget: 1000,
+		Lists:  []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	}))
+	// pilots have neither page size argument nor policy
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ factions { ships(first: 1) { items { pilots { items { name } } } } } }`,
+	})
+	assert.Nil(t, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, fmt.Sprintf("cost.Init: Query cost %v exceeds budget 1000", math.MaxInt), result.Errors[0].Message)
+	}
+}
+
+func TestCostAnalysis_ReportsCost(t *testing.T) {
+	schema := newCostTestSchema(pagination.NewCostAnalysis(pagination.CostConfig{
+		Budget: 2000,
+		Lists:  []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	}))
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: costTestQuery,
+	})
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"cost": 1653}, result.Extensions)
+}
+
+func TestCostAnalysis_RejectsQueriesAboveBudget(t *testing.T) {
+	schema := newCostTestSchema(pagination.NewCostAnalysis(pagination.CostConfig{
+		Budget: 1000,
+		Lists:  []*pagination.GraphQLListDefinitions{costTestShipListDef},
+	}))
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: costTestQuery,
+	})
+	assert.Nil(t, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "cost.Init: Query cost 1653 exceeds budget 1000", result.Errors[0].Message)
+	}
+}
+
+func TestListDefinitions_ListArgumentsApplyPolicy(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        newCostTestSchema(),
+		RequestString: `{ factions { ships(first: 51) { totalCount } } }`,
+	})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Page size 51 exceeds maximum 50", result.Errors[0].Message)
+		assert.Equal(t, map[string]interface{}{"code": "PAGE_SIZE_EXCEEDED"}, result.Errors[0].Extensions)
+	}
+}
@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"context"
+	"sync"
+)
+
+// EventSource delivers the events published on a topic
+type EventSource interface {
+	// Subscribe returns a channel receiving the events published on topic,
+	// which is closed once ctx is done
+	Subscribe(ctx context.Context, topic string) <-chan interface{}
+}
+
+// EventBus is an in-memory publish/subscribe EventSource.
+// Events are buffered per subscriber, and dropped for subscribers whose buffer
+// is full, so that slow subscribers never block publishers.
+type EventBus struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[string]map[chan interface{}]struct{}
+}
+
+// NewEventBus is an EventBus constructor, bufferSize being the number of
+// events buffered per subscriber (at least one)
+func NewEventBus(bufferSize int) *EventBus {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &EventBus{
+		bufferSize:  bufferSize,
+		subscribers: map[string]map[chan interface{}]struct{}{},
+	}
+}
+
+// Subscribe implements EventSource
+func (b *EventBus) Subscribe(ctx context.Context, topic string) <-chan interface{} {
+	events := make(chan interface{}, b.bufferSize)
+
+	b.mu.Lock()
+	if _, ok := b.subscribers[topic]; !ok {
+		b.subscribers[topic] = map[chan interface{}]struct{}{}
+	}
+	b.subscribers[topic][events] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[topic], events)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+		close(events)
+	}()
+
+	return events
+}
+
+// Publish sends event to the current subscribers of topic
+func (b *EventBus) Publish(topic string, event interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for events := range b.subscribers[topic] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers of topic
+func (b *EventBus) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[topic])
+}
@@ -0,0 +1,48 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_PublishesToTopicSubscribers(t *testing.T) {
+	bus := pagination.NewEventBus(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ships := bus.Subscribe(ctx, "ships")
+	factions := bus.Subscribe(ctx, "factions")
+	assert.Equal(t, 1, bus.Subscribers("ships"))
+
+	bus.Publish("ships", "X-Wing")
+	bus.Publish("ships", "Y-Wing")
+	// buffer is full, the event is dropped
+	bus.Publish("ships", "A-Wing")
+
+	assert.Equal(t, "X-Wing", <-ships)
+	assert.Equal(t, "Y-Wing", <-ships)
+	select {
+	case event := <-ships:
+		t.Fatalf("unexpected event %v", event)
+	case event := <-factions:
+		t.Fatalf("unexpected event %v", event)
+	default:
+	}
+}
+
+func TestEventBus_UnsubscribesWhenContextIsDone(t *testing.T) {
+	bus := pagination.NewEventBus(1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := bus.Subscribe(ctx, "ships")
+	cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.Equal(t, 0, bus.Subscribers("ships"))
+	// publishing without subscribers is a no-op
+	bus.Publish("ships", "X-Wing")
+}
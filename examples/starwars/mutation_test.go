@@ -6,6 +6,7 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/testutil"
+	"github.com/stratumn/graphql-pagination-go"
 	"github.com/stratumn/graphql-pagination-go/examples/starwars"
 )
 
@@ -54,3 +55,49 @@ func TestMutation_CorrectlyMutatesTheDataSet(t *testing.T) {
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func TestMutation_ReturnsTheNewShipWithItsListCursor(t *testing.T) {
+	query := `
+      mutation AddShipQuery($input: IntroduceShipInput!) {
+        introduceShip(input: $input) {
+          shipListItem {
+            item {
+              name
+            }
+            cursor
+            totalCount
+          }
+        }
+      }
+    `
+	params := map[string]interface{}{
+		"input": map[string]interface{}{
+			"shipName":  "Mon Calamari Cruiser",
+			"factionId": "1",
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         starwars.Schema,
+		RequestString:  query,
+		VariableValues: params,
+	})
+
+	// the new ship is appended to the rebels ships
+	rebelShips := starwars.GetRebels().Ships
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"introduceShip": map[string]interface{}{
+				"shipListItem": map[string]interface{}{
+					"item": map[string]interface{}{
+						"name": "Mon Calamari Cruiser",
+					},
+					"cursor":     string(pagination.OffsetToCursor(len(rebelShips) - 1)),
+					"totalCount": len(rebelShips),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
@@ -63,15 +63,22 @@ import (
  * }
  *
  * input IntroduceShipInput {
- *   clientMutationID: string!
+ *   clientMutationId: String
  *   shipName: string!
  *   factionId: ID!
  * }
  *
+ * type ShipListItem {
+ *   item: Ship
+ *   cursor: String!
+ *   totalCount: Int!
+ * }
+ *
  * input IntroduceShipPayload {
- *   clientMutationID: string!
+ *   clientMutationId: String
  *   ship: Ship
  *   faction: Faction
+ *   shipListItem: ShipListItem
  * }
  *
  * type Mutation {
@@ -158,6 +165,13 @@ func init() {
 	 *     items: [Ship]
 	 *     pageInfo: PageInfo!
 	 *   }
+	 *
+	 * listItemType implements the following type system shorthand:
+	 *   type ShipListItem {
+	 *     item: Ship
+	 *     cursor: String!
+	 *     totalCount: Int!
+	 *   }
 	 */
 	shipListDefinition := pagination.ListDefinitions(pagination.ListConfig{
 		Name:     "Ship",
@@ -191,11 +205,9 @@ func init() {
 					args := pagination.NewListArguments(p.Args)
 
 					// get ship objects from current faction
-					ships := []interface{}{}
+					ships := pagination.ArrayListSource{}
 					if faction, ok := p.Source.(*Faction); ok {
-						for _, shipID := range faction.Ships {
-							ships = append(ships, GetShip(shipID))
-						}
+						ships = factionShips(faction)
 					}
 					// let relay library figure out the result, given
 					// - the list of ships for this faction
@@ -245,15 +257,16 @@ func init() {
 	 *
 	 * It creates these two types implicitly:
 	 *   input IntroduceShipInput {
-	 *     clientMutationID: string!
+	 *     clientMutationId: String
 	 *     shipName: string!
 	 *     factionId: ID!
 	 *   }
 	 *
 	 *   input IntroduceShipPayload {
-	 *     clientMutationID: string!
+	 *     clientMutationId: String
 	 *     ship: Ship
 	 *     faction: Faction
+	 *     shipListItem: ShipListItem
 	 *   }
 	 */
 	shipMutation := pagination.MutationWithClientMutationID(pagination.MutationConfig{
@@ -285,6 +298,21 @@ func init() {
 					return nil, nil
 				},
 			},
+			"shipListItem": &graphql.Field{
+				Type: shipListDefinition.ListItemType(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if payload, ok := p.Source.(map[string]interface{}); ok {
+						faction := GetFaction(payload["factionId"].(string))
+						if faction == nil {
+							return nil, nil
+						}
+						// the new ship, its cursor in the faction ships and their updated count,
+						// so that clients can splice it into their cached list
+						return pagination.ListItemFromSource(factionShips(faction), GetShip(payload["shipId"].(string)), p.Context)
+					}
+					return nil, nil
+				},
+			},
 		},
 		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
 			// `inputMap` is a map with keys/fields as specified in `InputFields`
@@ -333,3 +361,12 @@ func init() {
 		panic(err)
 	}
 }
+
+// factionShips returns the ships of a faction as a list source
+func factionShips(faction *Faction) pagination.ArrayListSource {
+	ships := pagination.ArrayListSource{}
+	for _, shipID := range faction.Ships {
+		ships = append(ships, GetShip(shipID))
+	}
+	return ships
+}
@@ -0,0 +1,213 @@
+package pagination
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ExportFormat is the format of an export, see `Export`
+type ExportFormat string
+
+const (
+	// ExportNDJSON writes one JSON object per line
+	ExportNDJSON ExportFormat = "ndjson"
+	// ExportCSV writes one CSV record per item, after a header record
+	ExportCSV ExportFormat = "csv"
+)
+
+// ExportColumn maps a field of the item type to a column of an export
+type ExportColumn struct {
+	// Header is the name of the column, the name of the field by default
+	Header string `json:"header"`
+	Field  string `json:"field"`
+}
+
+// ExportConfig is the configuration of `Export`
+type ExportConfig struct {
+	Source ListSource   `json:"-"`
+	Format ExportFormat `json:"format"`
+	// ItemType resolves the columns of the items. Without it, NDJSON exports
+	// marshal the items as they are, and CSV exports are not possible.
+	ItemType *graphql.Object `json:"itemType"`
+	// Columns are the exported fields, all scalar and enum fields of ItemType
+	// sorted by name by default
+	Columns  []ExportColumn `json:"columns"`
+	PageSize int            `json:"pageSize"`
+	// After resumes an export after the cursor returned by a previous export.
+	// The CSV header is not written again.
+	After ListCursor `json:"after"`
+}
+
+// Export streams all the items of a list source to w, loading pages of
+// PageSize items (100 by default).
+// It returns the cursor of the last written item, to resume the export with
+// `ExportConfig.After` if it fails: pages are written entirely or not at all.
+func Export(w io.Writer, config ExportConfig, ctx context.Context) (ListCursor, error) {
+	columns, err := exportColumns(config)
+	if err != nil {
+		return config.After, err
+	}
+	pageSize := config.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	cursor := config.After
+	buf := &bytes.Buffer{}
+	csvWriter := csv.NewWriter(buf)
+	if config.Format == ExportCSV && cursor == "" {
+		headers := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = column.Header
+		}
+		csvWriter.Write(headers)
+	}
+	for {
+		args := NewListArguments(nil)
+		args.First, args.After = pageSize, cursor
+		list, err := config.Source.List(args, ctx)
+		if err != nil {
+			return cursor, err
+		}
+		for _, item := range list.Items {
+			row, err := exportRow(item, columns, config.ItemType, ctx)
+			if err != nil {
+				return cursor, err
+			}
+			if config.Format == ExportCSV {
+				record := make([]string, len(row))
+				for i, value := range row {
+					if value != nil {
+						record[i] = fmt.Sprintf("%v", value)
+					}
+				}
+				csvWriter.Write(record)
+				continue
+			}
+			var object interface{} = item
+			if config.ItemType != nil {
+				fields := make(map[string]interface{}, len(columns))
+				for i, column := range columns {
+					fields[column.Header] = row[i]
+				}
+				object = fields
+			}
+			b, err := json.Marshal(object)
+			if err != nil {
+				return cursor, err
+			}
+			buf.Write(append(b, '\n'))
+		}
+		csvWriter.Flush()
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return cursor, err
+		}
+		buf.Reset()
+		if len(list.Items) > 0 {
+			cursor = list.PageInfo.EndCursor
+		}
+		if !list.PageInfo.HasNextPage || len(list.Items) == 0 {
+			return cursor, nil
+		}
+	}
+}
+
+// exportColumns returns the columns of an export, checking that they are
+// scalar or enum fields of the item type
+func exportColumns(config ExportConfig) ([]ExportColumn, error) {
+	switch config.Format {
+	case ExportNDJSON:
+		if config.ItemType == nil {
+			return nil, nil
+		}
+	case ExportCSV:
+		if config.ItemType == nil {
+			return nil, errors.New("CSV exports need an item type")
+		}
+	default:
+		return nil, fmt.Errorf("Unknown export format %q", config.Format)
+	}
+
+	fields := config.ItemType.Fields()
+	columns := config.Columns
+	if len(columns) == 0 {
+		for name, field := range fields {
+			if isLeafType(field.Type) {
+				columns = append(columns, ExportColumn{Field: name})
+			}
+		}
+		sort.Slice(columns, func(i, j int) bool {
+			return columns[i].Field < columns[j].Field
+		})
+	}
+	result := make([]ExportColumn, len(columns))
+	for i, column := range columns {
+		field, ok := fields[column.Field]
+		if !ok {
+			return nil, fmt.Errorf("Unknown field %v of %v", column.Field, config.ItemType.Name())
+		}
+		if !isLeafType(field.Type) {
+			return nil, fmt.Errorf("Field %v of %v is not a scalar", column.Field, config.ItemType.Name())
+		}
+		if column.Header == "" {
+			column.Header = column.Field
+		}
+		result[i] = column
+	}
+	return result, nil
+}
+
+// isLeafType tells whether values of a type are scalars or enums
+func isLeafType(ttype graphql.Output) bool {
+	switch graphql.GetNullable(ttype).(type) {
+	case *graphql.Scalar, *graphql.Enum:
+		return true
+	}
+	return false
+}
+
+// exportRow resolves and serializes the columns of an item
+func exportRow(item interface{}, columns []ExportColumn, itemType *graphql.Object, ctx context.Context) ([]interface{}, error) {
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	row := make([]interface{}, len(columns))
+	fields := itemType.Fields()
+	for i, column := range columns {
+		field := fields[column.Field]
+		resolve := field.Resolve
+		if resolve == nil {
+			resolve = graphql.DefaultResolveFn
+		}
+		value, err := resolve(graphql.ResolveParams{
+			Source:  item,
+			Context: ctx,
+			Info: graphql.ResolveInfo{
+				FieldName:  column.Field,
+				ReturnType: field.Type,
+				ParentType: itemType,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		switch ttype := graphql.GetNullable(field.Type).(type) {
+		case *graphql.Scalar:
+			row[i] = ttype.Serialize(value)
+		case *graphql.Enum:
+			row[i] = ttype.Serialize(value)
+		}
+	}
+	return row, nil
+}
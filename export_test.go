@@ -0,0 +1,140 @@
+package pagination_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var exportTestUsers = pagination.ArrayListSource{
+	&user{ID: 1, Name: "Dan"},
+	&user{ID: 2, Name: "Nick"},
+	&user{ID: 3, Name: "Lee, Jr."},
+}
+
+var exportTestUserType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ExportUser",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+		},
+		"name": &graphql.Field{
+			Type: graphql.String,
+		},
+		"initial": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*user).Name[:1], nil
+			},
+		},
+		"friends": &graphql.Field{
+			Type: graphql.NewList(graphql.String),
+		},
+	},
+})
+
+func TestExport_WritesCSVWithItemTypeColumns(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cursor, err := pagination.Export(buf, pagination.ExportConfig{
+		Source:   exportTestUsers,
+		Format:   pagination.ExportCSV,
+		ItemType: exportTestUserType,
+		PageSize: 2,
+	}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(2), cursor)
+	assert.Equal(t, "id,initial,name\n1,D,Dan\n2,N,Nick\n3,L,\"Lee, Jr.\"\n", buf.String())
+}
+
+func TestExport_WritesNDJSONWithColumnMapping(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, err := pagination.Export(buf, pagination.ExportConfig{
+		Source:   exportTestUsers,
+		Format:   pagination.ExportNDJSON,
+		ItemType: exportTestUserType,
+		Columns: []pagination.ExportColumn{
+			{Field: "id"},
+			{Field: "name", Header: "fullName"},
+		},
+	}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"fullName":"Dan","id":1}
+{"fullName":"Nick","id":2}
+{"fullName":"Lee, Jr.","id":3}
+`, buf.String())
+}
+
+func TestExport_WritesNDJSONItemsWithoutItemType(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, err := pagination.Export(buf, pagination.ExportConfig{
+		Source: exportTestUsers[:1],
+		Format: pagination.ExportNDJSON,
+	}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"Dan\"}\n", buf.String())
+}
+
+func TestExport_ResumesFromReturnedCursor(t *testing.T) {
+	calls := 0
+	flaky := pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("Unavailable")
+		}
+		return exportTestUsers.List(args, ctx)
+	})
+	config := pagination.ExportConfig{
+		Source:   flaky,
+		Format:   pagination.ExportCSV,
+		ItemType: exportTestUserType,
+		Columns:  []pagination.ExportColumn{{Field: "name"}},
+		PageSize: 2,
+	}
+
+	buf := &bytes.Buffer{}
+	cursor, err := pagination.Export(buf, config, context.Background())
+	assert.EqualError(t, err, "Unavailable")
+	assert.Equal(t, pagination.OffsetToCursor(1), cursor)
+	assert.Equal(t, "name\nDan\nNick\n", buf.String())
+
+	config.After = cursor
+	cursor, err = pagination.Export(buf, config, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(2), cursor)
+	assert.Equal(t, "name\nDan\nNick\n\"Lee, Jr.\"\n", buf.String())
+}
+
+func TestExport_ChecksColumns(t *testing.T) {
+	_, err := pagination.Export(&bytes.Buffer{}, pagination.ExportConfig{
+		Source: exportTestUsers,
+		Format: pagination.ExportCSV,
+	}, context.Background())
+	assert.EqualError(t, err, "CSV exports need an item type")
+
+	_, err = pagination.Export(&bytes.Buffer{}, pagination.ExportConfig{
+		Source:   exportTestUsers,
+		Format:   pagination.ExportCSV,
+		ItemType: exportTestUserType,
+		Columns:  []pagination.ExportColumn{{Field: "friends"}},
+	}, context.Background())
+	assert.EqualError(t, err, "Field friends of ExportUser is not a scalar")
+
+	_, err = pagination.Export(&bytes.Buffer{}, pagination.ExportConfig{
+		Source:   exportTestUsers,
+		Format:   pagination.ExportNDJSON,
+		ItemType: exportTestUserType,
+		Columns:  []pagination.ExportColumn{{Field: "email"}},
+	}, context.Background())
+	assert.EqualError(t, err, "Unknown field email of ExportUser")
+
+	_, err = pagination.Export(&bytes.Buffer{}, pagination.ExportConfig{
+		Source: exportTestUsers,
+		Format: "xml",
+	}, context.Background())
+	assert.EqualError(t, err, `Unknown export format "xml"`)
+}
@@ -0,0 +1,162 @@
+package pagination
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ViewerFn returns an identifier of the viewer carried by the context
+type ViewerFn func(ctx context.Context) string
+
+// IdempotencyRecord is the payload of a mutation, along with the hash of the
+// input it was computed from, see `IdempotencyInputHash`
+type IdempotencyRecord struct {
+	InputHash string                 `json:"inputHash"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+// IdempotencyStore keeps mutation payloads so that retried requests replay
+// the original payload instead of mutating twice.
+type IdempotencyStore interface {
+	// Reserve atomically reserves key for a mutation about to run, and tells
+	// whether it did. If key holds a record, the record is returned instead,
+	// and if key is reserved by a running mutation, nothing is returned.
+	Reserve(key string) (*IdempotencyRecord, bool)
+	// Set stores the record of a reserved key
+	Set(key string, record *IdempotencyRecord)
+	// Release drops the reservation of a mutation which failed, so that it can be retried
+	Release(key string)
+}
+
+// ErrMutationInProgress is returned when a mutation is retried while it is still running
+var ErrMutationInProgress = errors.New("Mutation already in progress")
+
+// ErrClientMutationIDReused is returned when a client mutation id is reused
+// with a different input
+var ErrClientMutationIDReused = errors.New("Client mutation id reused with a different input")
+
+// IdempotencyInputHash returns the hash of a mutation input stored in
+// idempotency records, so that replays check that the input did not change
+func IdempotencyInputHash(input map[string]interface{}) string {
+	// maps are marshalled with sorted keys
+	b, _ := json.Marshal(input)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// IdempotencyKey returns the key under which the payload of a mutation is stored,
+// scoped by viewer, mutation name and client mutation id.
+func IdempotencyKey(viewer string, mutationName string, clientMutationID string) string {
+	return fmt.Sprintf("%d:%v%d:%v%v", len(viewer), viewer, len(mutationName), mutationName, clientMutationID)
+}
+
+// idempotencyEntry is a stored record, or a reservation if record is nil
+type idempotencyEntry struct {
+	record    *IdempotencyRecord
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore whose entries
+// expire after a fixed TTL, reservations included so that mutations which
+// never completed can be retried. It is safe for concurrent use.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]idempotencyEntry
+	nextSweep time.Time
+
+	// Now returns the current time, it defaults to `time.Now`
+	Now func() time.Time
+}
+
+// NewMemoryIdempotencyStore is a MemoryIdempotencyStore constructor
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		entries: map[string]idempotencyEntry{},
+		Now:     time.Now,
+	}
+}
+
+// Get returns the record stored under key, if any
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entry(key)
+	if !ok || entry.record == nil {
+		return nil, false
+	}
+	return entry.record, true
+}
+
+// Reserve implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Reserve(key string) (*IdempotencyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entry(key); ok {
+		return entry.record, false
+	}
+	s.put(key, nil)
+	return nil, true
+}
+
+// Set implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Set(key string, record *IdempotencyRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(key, record)
+}
+
+// Release implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok && entry.record == nil {
+		delete(s.entries, key)
+	}
+}
+
+// entry returns the entry of key unless it expired, the lock being held
+func (s *MemoryIdempotencyStore) entry(key string) (idempotencyEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return entry, false
+	}
+	if !s.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+// put stores an entry, the lock being held
+func (s *MemoryIdempotencyStore) put(key string, record *IdempotencyRecord) {
+	now := s.Now()
+	// expired entries are swept at most once per TTL
+	if !now.Before(s.nextSweep) {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(s.ttl)
+	}
+	s.entries[key] = idempotencyEntry{
+		record:    record,
+		expiresAt: now.Add(s.ttl),
+	}
+}
+
+// Len returns the number of stored entries, including reservations and
+// expired entries not swept yet
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
@@ -0,0 +1,91 @@
+package pagination_test
+
+import (
+	"testing"
+	"time"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKey_IsUnambiguous(t *testing.T) {
+	assert.NotEqual(t,
+		pagination.IdempotencyKey("a:b", "c", "d"),
+		pagination.IdempotencyKey("a", "b:c", "d"),
+	)
+	assert.Equal(t,
+		pagination.IdempotencyKey("a", "b", "c"),
+		pagination.IdempotencyKey("a", "b", "c"),
+	)
+}
+
+func TestMemoryIdempotencyStore_ExpiresEntries(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := pagination.NewMemoryIdempotencyStore(time.Minute)
+	store.Now = func() time.Time { return now }
+
+	record := &pagination.IdempotencyRecord{Payload: map[string]interface{}{"result": 1}}
+	store.Set("key", record)
+
+	got, ok := store.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, record, got)
+
+	_, ok = store.Get("other")
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = store.Get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryIdempotencyStore_SweepsExpiredEntries(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := pagination.NewMemoryIdempotencyStore(time.Minute)
+	store.Now = func() time.Time { return now }
+
+	store.Set("a", &pagination.IdempotencyRecord{})
+	store.Reserve("b")
+	assert.Equal(t, 2, store.Len())
+
+	now = now.Add(2 * time.Minute)
+	store.Set("c", &pagination.IdempotencyRecord{})
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestMemoryIdempotencyStore_ReservesKeys(t *testing.T) {
+	store := pagination.NewMemoryIdempotencyStore(time.Minute)
+
+	record, reserved := store.Reserve("key")
+	assert.Nil(t, record)
+	assert.True(t, reserved)
+	// running mutations are neither reserved again nor replayed
+	record, reserved = store.Reserve("key")
+	assert.Nil(t, record)
+	assert.False(t, reserved)
+	_, ok := store.Get("key")
+	assert.False(t, ok)
+
+	store.Release("key")
+	_, reserved = store.Reserve("key")
+	assert.True(t, reserved)
+
+	stored := &pagination.IdempotencyRecord{InputHash: "hash"}
+	store.Set("key", stored)
+	store.Release("key")
+	record, reserved = store.Reserve("key")
+	assert.Equal(t, stored, record)
+	assert.False(t, reserved)
+}
+
+func TestIdempotencyInputHash_DependsOnInput(t *testing.T) {
+	assert.Equal(t,
+		pagination.IdempotencyInputHash(map[string]interface{}{"a": 1, "b": "c"}),
+		pagination.IdempotencyInputHash(map[string]interface{}{"b": "c", "a": 1}),
+	)
+	assert.NotEqual(t,
+		pagination.IdempotencyInputHash(map[string]interface{}{"a": 1}),
+		pagination.IdempotencyInputHash(map[string]interface{}{"a": 2}),
+	)
+}
@@ -21,6 +21,10 @@ type ItemDefinitions struct {
 type ItemDefinitionsConfig struct {
 	IDFetcher   IDFetcherFn
 	TypeResolve graphql.ResolveTypeFn
+
+	// Authorize holds the per-type policies evaluated on refetched objects.
+	// Denied objects resolve to null with an `ErrNotAuthorized` error.
+	Authorize AuthorizationPolicies
 }
 
 // IDFetcherFn returns the the object from an id
@@ -67,7 +71,17 @@ func NewItemDefinitions(config ItemDefinitionsConfig) *ItemDefinitions {
 			if iid, ok := p.Args["id"]; ok {
 				id = fmt.Sprintf("%v", iid)
 			}
-			return config.IDFetcher(id, p.Info, p.Context)
+			if err := ContextError(p.Context); err != nil {
+				return nil, err
+			}
+			obj, err := config.IDFetcher(id, p.Info, p.Context)
+			if err != nil || obj == nil || config.Authorize == nil {
+				return obj, err
+			}
+			if err := authorizeItem(config.Authorize, ItemInterface, config.TypeResolve, obj, p.Info, p.Context); err != nil {
+				return nil, err
+			}
+			return obj, nil
 		},
 	}
 	return &ItemDefinitions{
@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ConcreteItemType returns the object type of an item of a list whose items
+// are of type itemType, which may be an object, an interface or a union.
+// Abstract types use their ResolveType function if any, or the IsTypeOf
+// function of their possible types. It returns nil if the type can't be
+// determined.
+func ConcreteItemType(itemType graphql.Output, item interface{}, info graphql.ResolveInfo, ctx context.Context) *graphql.Object {
+	if nonNull, ok := itemType.(*graphql.NonNull); ok {
+		itemType, _ = nonNull.OfType.(graphql.Output)
+	}
+	switch t := itemType.(type) {
+	case *graphql.Object:
+		return t
+	case *graphql.Interface:
+		if t.ResolveType != nil {
+			return t.ResolveType(graphql.ResolveTypeParams{Value: item, Info: info, Context: ctx})
+		}
+		return possibleTypeOf(info.Schema.PossibleTypes(t), item, info, ctx)
+	case *graphql.Union:
+		if t.ResolveType != nil {
+			return t.ResolveType(graphql.ResolveTypeParams{Value: item, Info: info, Context: ctx})
+		}
+		return possibleTypeOf(t.Types(), item, info, ctx)
+	}
+	return nil
+}
+
+// possibleTypeOf returns the first of types whose IsTypeOf function accepts item
+func possibleTypeOf(types []*graphql.Object, item interface{}, info graphql.ResolveInfo, ctx context.Context) *graphql.Object {
+	for _, possibleType := range types {
+		if possibleType.IsTypeOf == nil {
+			continue
+		}
+		if possibleType.IsTypeOf(graphql.IsTypeOfParams{Value: item, Info: info, Context: ctx}) {
+			return possibleType
+		}
+	}
+	return nil
+}
+
+// ForItemType returns the policy of a list whose items are of type itemType,
+// evaluating for each item the policy registered for its concrete type, see
+// `ConcreteItemType`. Items whose concrete type can't be determined are denied.
+func (policies AuthorizationPolicies) ForItemType(itemType graphql.Output) AuthorizeFn {
+	return func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+		objectType := ConcreteItemType(itemType, obj, info, ctx)
+		if objectType == nil {
+			return false, nil
+		}
+		return policies.Authorize(objectType.Name(), obj, info, ctx)
+	}
+}
+
+// TypedKey identifies an item of a list mixing several kinds of items, whose
+// keys are only unique per type
+type TypedKey struct {
+	Type string
+	Key  interface{}
+}
+
+// TypedItemKey returns the ItemKeyFn of a list whose items are of type
+// itemType, keying each item with the name of its concrete type along with the
+// key given by the function registered for this type (see `IdentityKey` for
+// types without function).
+// Interfaces without ResolveType function aren't supported.
+func TypedItemKey(itemType graphql.Output, keys map[string]ItemKeyFn) ItemKeyFn {
+	return func(item interface{}) interface{} {
+		typeName := ""
+		if objectType := ConcreteItemType(itemType, item, graphql.ResolveInfo{}, context.Background()); objectType != nil {
+			typeName = objectType.Name()
+		}
+		return TypedKey{
+			Type: typeName,
+			Key:  IdentityKey(item, keys[typeName]),
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var searchTestUserType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchUser",
+	Fields: graphql.Fields{
+		"name": &graphql.Field{
+			Type: graphql.String,
+		},
+	},
+	IsTypeOf: func(p graphql.IsTypeOfParams) bool {
+		_, ok := p.Value.(*user)
+		return ok
+	},
+})
+
+var searchTestPhotoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchPhoto",
+	Fields: graphql.Fields{
+		"width": &graphql.Field{
+			Type: graphql.Int,
+		},
+	},
+	IsTypeOf: func(p graphql.IsTypeOfParams) bool {
+		_, ok := p.Value.(*photo)
+		return ok
+	},
+})
+
+var searchTestResultType = graphql.NewUnion(graphql.UnionConfig{
+	Name:  "SearchResult",
+	Types: []*graphql.Object{searchTestUserType, searchTestPhotoType},
+})
+
+var searchTestResults = []interface{}{
+	&user{ID: 1, Name: "Dan"},
+	&photo{ID: 1, Width: 300},
+	&user{ID: 2, Name: "Nick"},
+	&photo{ID: 2, Width: 400},
+}
+
+func TestConcreteItemType(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, searchTestUserType, pagination.ConcreteItemType(searchTestResultType, searchTestResults[0], graphql.ResolveInfo{}, ctx))
+	assert.Equal(t, searchTestPhotoType, pagination.ConcreteItemType(graphql.NewNonNull(searchTestResultType), searchTestResults[1], graphql.ResolveInfo{}, ctx))
+	assert.Equal(t, searchTestUserType, pagination.ConcreteItemType(searchTestUserType, "anything", graphql.ResolveInfo{}, ctx))
+	assert.Nil(t, pagination.ConcreteItemType(searchTestResultType, "unknown", graphql.ResolveInfo{}, ctx))
+}
+
+func TestListDefinitions_UnionItemType(t *testing.T) {
+	searchListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "SearchResult",
+		ItemType: searchTestResultType,
+		Authorize: pagination.AuthorizationPolicies{
+			"SearchPhoto": func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+				return obj.(*photo).Width > 300, nil
+			},
+		}.ForItemType(searchTestResultType),
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"search": &graphql.Field{
+					Type: searchListDef.ListType,
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						items, err := searchListDef.AuthorizedItems(append(searchTestResults, "unknown"), p.Info, p.Context)
+						if err != nil {
+							return nil, err
+						}
+						return pagination.ListFromArray(items, pagination.NewListArguments(p.Args)), nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `{
+          search(first: 2, after: "YXJyYXljb25uZWN0aW9uOjA=") {
+            items {
+              __typename
+              ... on SearchUser { name }
+              ... on SearchPhoto { width }
+            }
+            totalCount
+          }
+        }`,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"search": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"__typename": "SearchUser", "name": "Nick"},
+				map[string]interface{}{"__typename": "SearchPhoto", "width": 400},
+			},
+			"totalCount": 3,
+		},
+	}, result.Data)
+}
+
+func TestTypedItemKey_KeysItemsPerType(t *testing.T) {
+	itemKey := pagination.TypedItemKey(searchTestResultType, map[string]pagination.ItemKeyFn{
+		"SearchUser": func(item interface{}) interface{} {
+			return item.(*user).ID
+		},
+		"SearchPhoto": func(item interface{}) interface{} {
+			return item.(*photo).ID
+		},
+	})
+	assert.Equal(t, pagination.TypedKey{Type: "SearchUser", Key: 1}, itemKey(searchTestResults[0]))
+
+	// users and photos have the same ids
+	index := pagination.NewListIndex(searchTestResults, itemKey)
+	assert.Equal(t, pagination.OffsetToCursor(3), index.Cursor(&photo{ID: 2}))
+	assert.Equal(t, pagination.OffsetToCursor(2), index.Cursor(&user{ID: 2}))
+
+	patches := pagination.DiffLists(
+		pagination.ListFromArray(searchTestResults, pagination.NewListArguments(nil)),
+		pagination.ListFromArray([]interface{}{searchTestResults[0], searchTestResults[2], searchTestResults[3]}, pagination.NewListArguments(nil)),
+		itemKey,
+		nil,
+	)
+	// items following the removed one moved to the previous offsets
+	assert.Equal(t, []*pagination.ListPatch{
+		{Op: pagination.ListPatchRemove, Cursor: pagination.OffsetToCursor(1)},
+		{Op: pagination.ListPatchRemove, Cursor: pagination.OffsetToCursor(2)},
+		{Op: pagination.ListPatchRemove, Cursor: pagination.OffsetToCursor(3)},
+		{Op: pagination.ListPatchInsert, Cursor: pagination.OffsetToCursor(1), AfterCursor: pagination.OffsetToCursor(0), Item: searchTestResults[2]},
+		{Op: pagination.ListPatchInsert, Cursor: pagination.OffsetToCursor(2), AfterCursor: pagination.OffsetToCursor(1), Item: searchTestResults[3]},
+		{Op: pagination.ListPatchTotalCount, TotalCount: 3},
+	}, patches)
+}
@@ -0,0 +1,98 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents
+const JSONAPIContentType = "application/vnd.api+json"
+
+// JSONAPILinks are the pagination links of a JSON:API document, missing pages
+// having empty links
+type JSONAPILinks struct {
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// JSONAPIDocument is a JSON:API document of a page of resource objects
+type JSONAPIDocument struct {
+	Data  []interface{}          `json:"data"`
+	Links JSONAPILinks           `json:"links"`
+	Meta  map[string]interface{} `json:"meta"`
+}
+
+// JSONAPIArguments returns the list arguments of the `page[size]`,
+// `page[after]` and `page[before]` query parameters of the JSON:API cursor
+// pagination profile, applying the policy.
+// The page size is the number of items after the `after` cursor, or before
+// the `before` cursor if there is no `after` cursor.
+func (p ListPolicy) JSONAPIArguments(query url.Values) (ListArguments, error) {
+	args := NewListArguments(nil)
+	args.After = ListCursor(query.Get("page[after]"))
+	args.Before = ListCursor(query.Get("page[before]"))
+	if value := query.Get("page[size]"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return args, &ListError{
+				Code:    "INVALID_ARGUMENT",
+				Message: fmt.Sprintf("Invalid page[size] %q", value),
+			}
+		}
+		if args.Before != "" && args.After == "" {
+			args.Last = size
+		} else {
+			args.First = size
+		}
+	}
+	return p.Apply(args)
+}
+
+// NewJSONAPIDocument returns the JSON:API document of a page loaded with the
+// arguments of `JSONAPIArguments`, the items being the resource objects.
+// Links are relative to the request URL u, keeping its other query
+// parameters, and the total count of the list is `meta.totalCount`.
+func NewJSONAPIDocument(u *url.URL, args ListArguments, list *List) *JSONAPIDocument {
+	pageSize := args.First
+	if pageSize == -1 {
+		pageSize = args.Last
+	}
+	link := func(after, before ListCursor) string {
+		query := u.Query()
+		for _, name := range []string{"page[size]", "page[after]", "page[before]"} {
+			query.Del(name)
+		}
+		if pageSize != -1 {
+			query.Set("page[size]", strconv.Itoa(pageSize))
+		}
+		if after != "" {
+			query.Set("page[after]", string(after))
+		}
+		if before != "" {
+			query.Set("page[before]", string(before))
+		}
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return target.String()
+	}
+
+	doc := &JSONAPIDocument{
+		Data:  list.Items,
+		Links: JSONAPILinks{First: link("", "")},
+		Meta: map[string]interface{}{
+			"totalCount": list.TotalCount,
+		},
+	}
+	if doc.Data == nil {
+		doc.Data = []interface{}{}
+	}
+	hasPrev, hasNext := pagesAround(args, list)
+	if hasPrev {
+		doc.Links.Prev = link("", list.PageInfo.StartCursor)
+	}
+	if hasNext {
+		doc.Links.Next = link(list.PageInfo.EndCursor, "")
+	}
+	return doc
+}
@@ -0,0 +1,78 @@
+package pagination_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonAPITestPolicy = pagination.ListPolicy{DefaultPageSize: 2, MaxPageSize: 10}
+
+func jsonAPIPage(t *testing.T, target string) *pagination.JSONAPIDocument {
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := jsonAPITestPolicy.JSONAPIArguments(u.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := pagination.ArrayListSource(arrayListTestLetters).List(args, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pagination.NewJSONAPIDocument(u, args, list)
+}
+
+func TestJSONAPIArguments(t *testing.T) {
+	after, before := pagination.OffsetToCursor(0), pagination.OffsetToCursor(4)
+
+	args, err := jsonAPITestPolicy.JSONAPIArguments(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, args.First)
+
+	args, err = jsonAPITestPolicy.JSONAPIArguments(url.Values{"page[size]": {"3"}, "page[after]": {string(after)}})
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.ListArguments{First: 3, Last: -1, After: after}, args)
+
+	args, err = jsonAPITestPolicy.JSONAPIArguments(url.Values{"page[size]": {"3"}, "page[before]": {string(before)}})
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.ListArguments{First: -1, Last: 3, Before: before}, args)
+
+	_, err = jsonAPITestPolicy.JSONAPIArguments(url.Values{"page[size]": {"0"}})
+	assert.EqualError(t, err, `Invalid page[size] "0"`)
+	_, err = jsonAPITestPolicy.JSONAPIArguments(url.Values{"page[size]": {"11"}})
+	assert.EqualError(t, err, "Page size 11 exceeds maximum 10")
+}
+
+func TestNewJSONAPIDocument_LinksPages(t *testing.T) {
+	doc := jsonAPIPage(t, "/letters?filter[vowel]=false")
+	assert.Equal(t, []interface{}{"A", "B"}, doc.Data)
+	assert.Equal(t, map[string]interface{}{"totalCount": 5}, doc.Meta)
+	assert.Equal(t, pagination.JSONAPILinks{
+		First: "/letters?filter%5Bvowel%5D=false&page%5Bsize%5D=2",
+		Next:  "/letters?filter%5Bvowel%5D=false&page%5Bafter%5D=" + url.QueryEscape(string(pagination.OffsetToCursor(1))) + "&page%5Bsize%5D=2",
+	}, doc.Links)
+
+	doc = jsonAPIPage(t, doc.Links.Next)
+	assert.Equal(t, []interface{}{"C", "D"}, doc.Data)
+	assert.NotEmpty(t, doc.Links.Next)
+	assert.Equal(t, "/letters?filter%5Bvowel%5D=false&page%5Bbefore%5D="+url.QueryEscape(string(pagination.OffsetToCursor(2)))+"&page%5Bsize%5D=2", doc.Links.Prev)
+
+	doc = jsonAPIPage(t, doc.Links.Prev)
+	assert.Equal(t, []interface{}{"A", "B"}, doc.Data)
+	assert.Empty(t, doc.Links.Prev)
+	assert.NotEmpty(t, doc.Links.Next)
+}
+
+func TestNewJSONAPIDocument_MarshalsEmptyPages(t *testing.T) {
+	u, _ := url.Parse("/letters")
+	doc := pagination.NewJSONAPIDocument(u, pagination.NewListArguments(nil), &pagination.List{})
+	b, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": [], "links": {"first": "/letters"}, "meta": {"totalCount": 0}}`, string(b))
+}
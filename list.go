@@ -1,6 +1,12 @@
 package pagination
 
-import "github.com/graphql-go/graphql"
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
 
 // ListArgs returns a GraphQLFieldConfigArgumentMap appropriate to include
 // on a field whose return type is a list type.
@@ -29,14 +35,79 @@ func NewListArgs(configMap graphql.FieldConfigArgument) graphql.FieldConfigArgum
 
 // ListConfig is the configuration object for list
 type ListConfig struct {
-	Name       string          `json:"name"`
-	ItemType   *graphql.Object `json:"itemType"`
-	ListFields graphql.Fields  `json:"listFields"`
+	Name string `json:"name"`
+	// ItemType may be an object, an interface or a union, see `ConcreteItemType`
+	ItemType   graphql.Output `json:"itemType"`
+	ListFields graphql.Fields `json:"listFields"`
+
+	// Authorize is the policy filtering the items of this list, see
+	// `AuthorizedItems` and `Load`
+	Authorize AuthorizeFn `json:"-"`
+	// Policy limits the pages of this list, see `ListArguments`
+	Policy ListPolicy `json:"policy"`
 }
 
 // GraphQLListDefinitions is the GraphQL object type for a list
 type GraphQLListDefinitions struct {
-	ListType *graphql.Object `json:"listType"`
+	ListType  *graphql.Object `json:"listType"`
+	Authorize AuthorizeFn     `json:"-"`
+	Policy    ListPolicy      `json:"policy"`
+
+	// types are the types of optional features, built on first use
+	types *listTypes
+}
+
+// listTypes are the lazily built types of a list, shared by copies of its
+// definitions
+type listTypes struct {
+	name     string
+	itemType graphql.Output
+
+	mu       sync.Mutex
+	bySuffix map[string]*graphql.Object
+}
+
+// lazyType returns the type of the list named with suffix, built by build
+// from the name and item type of the list on first use, so that lists only
+// reserve the type names of the features they use
+func (d *GraphQLListDefinitions) lazyType(suffix string, build func(name string, itemType graphql.Output) *graphql.Object) *graphql.Object {
+	d.types.mu.Lock()
+	defer d.types.mu.Unlock()
+	if t, ok := d.types.bySuffix[suffix]; ok {
+		return t
+	}
+	t := build(d.types.name, d.types.itemType)
+	d.types.bySuffix[suffix] = t
+	return t
+}
+
+// ListArguments returns the list arguments of a field, applying the list policy
+func (d *GraphQLListDefinitions) ListArguments(args map[string]interface{}) (ListArguments, error) {
+	return d.Policy.Apply(NewListArguments(args))
+}
+
+// AuthorizedItems filters data with the list policy, see `AuthorizedItems`
+func (d *GraphQLListDefinitions) AuthorizedItems(data []interface{}, info graphql.ResolveInfo, ctx context.Context) ([]interface{}, error) {
+	return AuthorizedItems(data, d.Authorize, info, ctx)
+}
+
+// AuthorizedSource returns source filtered by the list policy, so that
+// cursors and totalCount only account for visible items. Only the items of
+// `ArrayListSource`s can be filtered, other sources have to filter items
+// themselves and fail if the list has a policy.
+func (d *GraphQLListDefinitions) AuthorizedSource(source ListSource, info graphql.ResolveInfo, ctx context.Context) (ListSource, error) {
+	if d.Authorize == nil {
+		return source, nil
+	}
+	items, ok := source.(ArrayListSource)
+	if !ok {
+		return nil, fmt.Errorf("Cannot authorize the items of %T", source)
+	}
+	items, err := d.AuthorizedItems(items, info, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ArrayListSource(items), nil
 }
 
 /*
@@ -93,6 +164,13 @@ func ListDefinitions(config ListConfig) *GraphQLListDefinitions {
 	}
 
 	return &GraphQLListDefinitions{
-		ListType: listType,
+		ListType:  listType,
+		Authorize: config.Authorize,
+		Policy:    config.Policy,
+		types: &listTypes{
+			name:     config.Name,
+			itemType: config.ItemType,
+			bySuffix: map[string]*graphql.Object{},
+		},
 	}
 }
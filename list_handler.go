@@ -0,0 +1,152 @@
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+)
+
+// listHandlerStatus maps the codes of list errors to HTTP statuses, others
+// being bad requests
+var listHandlerStatus = map[string]int{
+	ErrStaleCursor.Code: http.StatusGone,
+	ErrTimeout.Code:     http.StatusGatewayTimeout,
+	ErrCanceled.Code:    http.StatusServiceUnavailable,
+}
+
+// ListHandler adapts the resolver of a list field to a REST endpoint.
+// Query parameters are the arguments of the resolver, `first` and `last`
+// being parsed as integers, and the `*List` it returns is served as JSON with
+// an `X-Total-Count` header and RFC 8288 `Link` headers to the first, next
+// and previous pages.
+// List errors are served with their code
+// (and a 4xx or 5xx status), other errors with a 500 status.
+func ListHandler(resolve graphql.FieldResolveFn) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := listHandlerArgs(r.URL.Query())
+		if err != nil {
+			writeListHandlerError(w, err)
+			return
+		}
+		res, err := resolve(graphql.ResolveParams{
+			Args:    params,
+			Context: r.Context(),
+		})
+		if err != nil {
+			writeListHandlerError(w, err)
+			return
+		}
+		var list *List
+		switch res := res.(type) {
+		case *List:
+			list = res
+		case List:
+			list = &res
+		}
+		if list == nil {
+			writeListHandlerError(w, fmt.Errorf("Unexpected result %T", res))
+			return
+		}
+
+		for _, link := range listLinks(r.URL, NewListArguments(params), list) {
+			w.Header().Add("Link", link)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(list.TotalCount))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	})
+}
+
+// listHandlerArgs returns the resolver arguments of query parameters
+func listHandlerArgs(query url.Values) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(query))
+	for name := range query {
+		value := query.Get(name)
+		if name != "first" && name != "last" {
+			args[name] = value
+			continue
+		}
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return nil, &ListError{
+				Code:    "INVALID_ARGUMENT",
+				Message: fmt.Sprintf("Invalid %v %q", name, value),
+			}
+		}
+		args[name] = size
+	}
+	return args, nil
+}
+
+// listLinks returns the Link header values of the pages around list
+func listLinks(u *url.URL, args ListArguments, list *List) []string {
+	pageSize := args.First
+	if pageSize == -1 {
+		pageSize = args.Last
+	}
+	link := func(rel string, first, last int, after, before ListCursor) string {
+		query := u.Query()
+		for _, name := range []string{"first", "last", "after", "before"} {
+			query.Del(name)
+		}
+		if first != -1 {
+			query.Set("first", strconv.Itoa(first))
+		}
+		if last != -1 {
+			query.Set("last", strconv.Itoa(last))
+		}
+		if after != "" {
+			query.Set("after", string(after))
+		}
+		if before != "" {
+			query.Set("before", string(before))
+		}
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%v>; rel=%q", target.String(), rel)
+	}
+
+	links := []string{link("first", pageSize, -1, "", "")}
+	hasPrev, hasNext := pagesAround(args, list)
+	if hasNext {
+		links = append(links, link("next", pageSize, -1, list.PageInfo.EndCursor, ""))
+	}
+	if hasPrev {
+		links = append(links, link("prev", -1, pageSize, "", list.PageInfo.StartCursor))
+	}
+	return links
+}
+
+// pagesAround tells whether there are pages before and after list, for links
+// to the previous and next pages
+func pagesAround(args ListArguments, list *List) (bool, bool) {
+	if len(list.Items) == 0 {
+		return false, false
+	}
+	// pages before the `after` cursor or after the `before` cursor exist even
+	// if PageInfo only tells about the direction of the pagination
+	return list.PageInfo.HasPreviousPage || args.After != "", list.PageInfo.HasNextPage || args.Before != ""
+}
+
+// writeListHandlerError serves an error as JSON
+func writeListHandlerError(w http.ResponseWriter, err error) {
+	body := map[string]interface{}{
+		"message": err.Error(),
+	}
+	status := http.StatusInternalServerError
+	var listErr *ListError
+	if errors.As(err, &listErr) {
+		body["code"] = listErr.Code
+		status = http.StatusBadRequest
+		if s, ok := listHandlerStatus[listErr.Code]; ok {
+			status = s
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
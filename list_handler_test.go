@@ -0,0 +1,105 @@
+package pagination_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var listHandlerTestLetters = pagination.ListDefinitions(pagination.ListConfig{
+	Name: "HandlerLetter",
+	ItemType: graphql.NewObject(graphql.ObjectConfig{
+		Name: "HandlerLetter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{Type: graphql.String},
+		},
+	}),
+	Policy: pagination.ListPolicy{DefaultPageSize: 2, MaxPageSize: 10},
+})
+
+func listHandlerTestResolve(p graphql.ResolveParams) (interface{}, error) {
+	if p.Args["stale"] == "true" {
+		return nil, pagination.ErrStaleCursor
+	}
+	return listHandlerTestLetters.Load(pagination.ArrayListSource(arrayListTestLetters), p)
+}
+
+func serveList(target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	pagination.ListHandler(listHandlerTestResolve).ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestListHandler_ServesPageWithLinks(t *testing.T) {
+	after := url.QueryEscape(string(pagination.OffsetToCursor(0)))
+	w := serveList("/letters?first=2&after=" + after + "&sort=asc")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, []string{
+		`</letters?first=2&sort=asc>; rel="first"`,
+		`</letters?after=` + url.QueryEscape(string(pagination.OffsetToCursor(2))) + `&first=2&sort=asc>; rel="next"`,
+		`</letters?before=` + url.QueryEscape(string(pagination.OffsetToCursor(1))) + `&last=2&sort=asc>; rel="prev"`,
+	}, w.Header().Values("Link"))
+
+	var list pagination.List
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, []interface{}{"B", "C"}, list.Items)
+	assert.Equal(t, pagination.OffsetToCursor(2), list.PageInfo.EndCursor)
+	assert.True(t, list.PageInfo.HasNextPage)
+	assert.Equal(t, 5, list.TotalCount)
+}
+
+func TestListHandler_LinksLastPage(t *testing.T) {
+	w := serveList("/letters?last=2")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{
+		`</letters?first=2>; rel="first"`,
+		`</letters?before=` + url.QueryEscape(string(pagination.OffsetToCursor(3))) + `&last=2>; rel="prev"`,
+	}, w.Header().Values("Link"))
+	assert.JSONEq(t, `{
+		"items": ["D", "E"],
+		"pageInfo": {
+			"startCursor": "`+string(pagination.OffsetToCursor(3))+`",
+			"endCursor": "`+string(pagination.OffsetToCursor(4))+`",
+			"hasPreviousPage": true,
+			"hasNextPage": false
+		},
+		"totalCount": 5
+	}`, w.Body.String())
+}
+
+func TestListHandler_ServesListErrors(t *testing.T) {
+	w := serveList("/letters?first=two")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code": "INVALID_ARGUMENT", "message": "Invalid first \"two\""}`, w.Body.String())
+
+	w = serveList("/letters?first=50")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code": "PAGE_SIZE_EXCEEDED", "message": "Page size 50 exceeds maximum 10"}`, w.Body.String())
+
+	w = serveList("/letters?stale=true")
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.JSONEq(t, `{"code": "STALE_CURSOR", "message": "Stale cursor"}`, w.Body.String())
+}
+
+func TestListHandler_ServesTimeoutsOfLoad(t *testing.T) {
+	handler := pagination.ListHandler(func(p graphql.ResolveParams) (interface{}, error) {
+		return listHandlerTestLetters.Load(pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+			return pagination.ListFromArray(arrayListTestLetters[:1], args), pagination.ErrTimeout
+		}), p)
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/letters", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"code": "TIMEOUT", "message": "Timeout"}`, w.Body.String())
+}
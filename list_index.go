@@ -0,0 +1,127 @@
+package pagination
+
+import (
+	"context"
+	"reflect"
+)
+
+// Identifiable is implemented by items which know the key identifying them in
+// a list, see `IdentityKey`
+type Identifiable interface {
+	IdentityKey() interface{}
+}
+
+// identityPointer identifies a map, slice or func, which are not comparable,
+// by its address
+type identityPointer struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// IdentityKey returns the key identifying item, given by keyFn if not nil, by
+// `Identifiable` items, or the item itself otherwise, so that pointers are
+// compared by identity rather than by content. Maps, slices and funcs are
+// compared by address too. Other items which are not comparable, e.g. structs
+// holding slices, are compared by content and need a keyFn or to implement
+// Identifiable to be told apart from equal items.
+func IdentityKey(item interface{}, keyFn ItemKeyFn) interface{} {
+	if keyFn != nil {
+		return keyFn(item)
+	}
+	if identifiable, ok := item.(Identifiable); ok {
+		return identifiable.IdentityKey()
+	}
+	if item == nil || reflect.TypeOf(item).Comparable() {
+		return item
+	}
+	value := reflect.ValueOf(item)
+	switch value.Kind() {
+	case reflect.Map, reflect.Func:
+		return identityPointer{typ: value.Type(), ptr: value.Pointer()}
+	case reflect.Slice:
+		return identityPointer{typ: value.Type(), ptr: value.Pointer(), len: value.Len()}
+	}
+	return valueKey(item)
+}
+
+// CursorForObjectInListByKey returns the cursor associated with an object in an
+// array, comparing identity keys instead of contents, see `IdentityKey`
+func CursorForObjectInListByKey(data []interface{}, object interface{}, keyFn ItemKeyFn) ListCursor {
+	key := IdentityKey(object, keyFn)
+	for i, d := range data {
+		if IdentityKey(d, keyFn) == key {
+			return OffsetToCursor(i)
+		}
+	}
+	return ""
+}
+
+// CursorForObjectInPage returns the cursor of an object in a page of a list
+// which doesn't use offsets as cursors, given the ItemCursorFn of the list
+// (e.g. `OrderedItemCursor`)
+func CursorForObjectInPage(list *List, object interface{}, keyFn ItemKeyFn, itemCursor ItemCursorFn) ListCursor {
+	if itemCursor == nil {
+		itemCursor = OffsetItemCursor
+	}
+	key := IdentityKey(object, keyFn)
+	for i, item := range list.Items {
+		if IdentityKey(item, keyFn) == key {
+			return itemCursor(list, i)
+		}
+	}
+	return ""
+}
+
+// ListIndex is a static array indexed by identity keys, so that the cursor of
+// an object is found in constant time. It is a ListSource, see `ListFromArray`.
+type ListIndex struct {
+	data    []interface{}
+	keyFn   ItemKeyFn
+	offsets map[interface{}]int
+}
+
+// NewListIndex is a ListIndex constructor, keyFn may be nil (see `IdentityKey`).
+// If several items have the same key, the first one is indexed.
+func NewListIndex(data []interface{}, keyFn ItemKeyFn) *ListIndex {
+	index := &ListIndex{
+		data:    data,
+		keyFn:   keyFn,
+		offsets: make(map[interface{}]int, len(data)),
+	}
+	for i, item := range data {
+		key := IdentityKey(item, keyFn)
+		if _, ok := index.offsets[key]; !ok {
+			index.offsets[key] = i
+		}
+	}
+	return index
+}
+
+// Offset returns the offset of object in the array
+func (index *ListIndex) Offset(object interface{}) (int, bool) {
+	offset, ok := index.offsets[IdentityKey(object, index.keyFn)]
+	return offset, ok
+}
+
+// Cursor returns the cursor of object, or an empty cursor if it is not in the array
+func (index *ListIndex) Cursor(object interface{}) ListCursor {
+	offset, ok := index.Offset(object)
+	if !ok {
+		return ""
+	}
+	return OffsetToCursor(offset)
+}
+
+// List implements ListSource
+func (index *ListIndex) List(args ListArguments, ctx context.Context) (*List, error) {
+	if err := ContextError(ctx); err != nil {
+		return nil, err
+	}
+	return ListFromArray(index.data, args), nil
+}
+
+// CursorFor implements CursorLocator
+func (index *ListIndex) CursorFor(object interface{}, ctx context.Context) (ListCursor, error) {
+	return index.Cursor(object), nil
+}
@@ -0,0 +1,94 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type identifiableUser struct {
+	ID   int
+	Name string
+}
+
+func (u identifiableUser) IdentityKey() interface{} {
+	return u.ID
+}
+
+func TestIdentityKey(t *testing.T) {
+	dan := &user{ID: 1, Name: "Dan"}
+	assert.Equal(t, 1, pagination.IdentityKey(dan, func(item interface{}) interface{} {
+		return item.(*user).ID
+	}))
+	assert.Equal(t, 2, pagination.IdentityKey(identifiableUser{ID: 2}, nil))
+	// pointers are compared by identity
+	assert.True(t, pagination.IdentityKey(dan, nil) == dan)
+	assert.False(t, pagination.IdentityKey(&user{ID: 1, Name: "Dan"}, nil) == dan)
+}
+
+func TestCursorForObjectInListByKey_MatchesIdentityRatherThanContent(t *testing.T) {
+	first := &user{ID: 1, Name: "Dan"}
+	second := &user{ID: 1, Name: "Dan"}
+	users := []interface{}{first, second}
+
+	assert.Equal(t, pagination.OffsetToCursor(0), pagination.CursorForObjectInList(users, second))
+	assert.Equal(t, pagination.OffsetToCursor(1), pagination.CursorForObjectInListByKey(users, second, nil))
+	assert.Equal(t, pagination.ListCursor(""), pagination.CursorForObjectInListByKey(users, &user{ID: 1}, nil))
+}
+
+func TestCursorForObjectInListByKey_MatchesMapsByIdentity(t *testing.T) {
+	first := map[string]interface{}{"id": 1}
+	second := map[string]interface{}{"id": 1}
+	items := []interface{}{first, second}
+
+	assert.Equal(t, pagination.OffsetToCursor(1), pagination.CursorForObjectInListByKey(items, second, nil))
+	assert.Equal(t, pagination.ListCursor(""), pagination.CursorForObjectInListByKey(items, map[string]interface{}{"id": 1}, nil))
+	index := pagination.NewListIndex(items, nil)
+	assert.Equal(t, pagination.OffsetToCursor(1), index.Cursor(second))
+}
+
+func TestListIndex_FindsCursorsByKey(t *testing.T) {
+	users := []interface{}{
+		identifiableUser{ID: 1, Name: "Dan"},
+		identifiableUser{ID: 2, Name: "Nick"},
+		identifiableUser{ID: 3, Name: "Lee"},
+	}
+	index := pagination.NewListIndex(users, nil)
+
+	// the name may have changed since the index was built
+	cursor, err := index.CursorFor(identifiableUser{ID: 2, Name: "Nicolas"}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(1), cursor)
+	assert.Equal(t, pagination.ListCursor(""), index.Cursor(identifiableUser{ID: 4}))
+
+	list, err := index.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 1,
+		"after": string(cursor),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{users[2]}, list.Items)
+
+	item, err := pagination.ListItemFromSource(index, users[0], context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(0), item.Cursor)
+}
+
+func TestCursorForObjectInPage_UsesListItemCursors(t *testing.T) {
+	c := newLetterCollection("A", "B", "C", "D", "E")
+	page, err := c.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(pagination.KeyToCursor("B")),
+	}), context.Background())
+	assert.NoError(t, err)
+
+	itemCursor := pagination.OrderedItemCursor(letterKey)
+	assert.Equal(t, pagination.KeyToCursor("D"), pagination.CursorForObjectInPage(page, "D", nil, itemCursor))
+	assert.Equal(t, pagination.ListCursor(""), pagination.CursorForObjectInPage(page, "E", nil, itemCursor))
+
+	offsetPage := pagination.ListFromArray(arrayListTestLetters, pagination.NewListArguments(map[string]interface{}{
+		"after": string(pagination.OffsetToCursor(1)),
+	}))
+	assert.Equal(t, pagination.OffsetToCursor(3), pagination.CursorForObjectInPage(offsetPage, "D", nil, nil))
+}
@@ -0,0 +1,100 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ErrItemNotInList is returned when an object can't be found in a list
+var ErrItemNotInList = errors.New("Item not in list")
+
+// ListItem is an item along with its position in a list. Mutations creating
+// items return it so that clients can splice the new item into their cached list.
+type ListItem struct {
+	Item       interface{} `json:"item"`
+	Cursor     ListCursor  `json:"cursor"`
+	TotalCount int         `json:"totalCount"`
+}
+
+// ListItemType describes an item along with its position in the list, see
+// `ListItem`. It is built on first use.
+func (d *GraphQLListDefinitions) ListItemType() *graphql.Object {
+	return d.lazyType("ListItem", func(name string, itemType graphql.Output) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        name + "ListItem",
+			Description: "An item along with its position in the list.",
+
+			Fields: graphql.Fields{
+				"item": &graphql.Field{
+					Type:        itemType,
+					Description: "The item.",
+				},
+				"cursor": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "The cursor of the item in the list.",
+				},
+				"totalCount": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Count of all list items.",
+				},
+			},
+		})
+	})
+}
+
+// ListItemFromSource returns object along with its cursor in source and the
+// updated count of the list.
+// Sources implementing CursorLocator are asked for the cursor directly,
+// otherwise the whole list is loaded to find the object.
+func ListItemFromSource(source ListSource, object interface{}, ctx context.Context) (*ListItem, error) {
+	if locator, ok := source.(CursorLocator); ok {
+		cursor, err := locator.CursorFor(object, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if cursor == "" {
+			return nil, ErrItemNotInList
+		}
+		args := NewListArguments(nil)
+		args.First = 0
+		list, err := source.List(args, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &ListItem{
+			Item:       object,
+			Cursor:     cursor,
+			TotalCount: list.TotalCount,
+		}, nil
+	}
+
+	list, err := source.List(NewListArguments(nil), ctx)
+	if err != nil {
+		return nil, err
+	}
+	offset := -1
+	for i, item := range list.Items {
+		if reflect.DeepEqual(item, object) {
+			offset = i
+			break
+		}
+	}
+	if offset == -1 {
+		return nil, ErrItemNotInList
+	}
+	// the cursor of the object is the end cursor of the window ending with it
+	args := NewListArguments(nil)
+	args.First = offset + 1
+	window, err := source.List(args, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ListItem{
+		Item:       object,
+		Cursor:     window.PageInfo.EndCursor,
+		TotalCount: list.TotalCount,
+	}, nil
+}
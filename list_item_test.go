@@ -0,0 +1,43 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListItemFromSource_UsesCursorLocator(t *testing.T) {
+	item, err := pagination.ListItemFromSource(pagination.ArrayListSource(arrayListTestLetters), "C", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &pagination.ListItem{
+		Item:       "C",
+		Cursor:     pagination.OffsetToCursor(2),
+		TotalCount: 5,
+	}, item)
+}
+
+func TestListItemFromSource_ScansOtherSources(t *testing.T) {
+	source := pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+		return pagination.ListFromArray(arrayListTestLetters, args), nil
+	})
+	item, err := pagination.ListItemFromSource(source, "D", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &pagination.ListItem{
+		Item:       "D",
+		Cursor:     pagination.OffsetToCursor(3),
+		TotalCount: 5,
+	}, item)
+}
+
+func TestListItemFromSource_ReturnsErrorForMissingItems(t *testing.T) {
+	_, err := pagination.ListItemFromSource(pagination.ArrayListSource(arrayListTestLetters), "Z", context.Background())
+	assert.Equal(t, pagination.ErrItemNotInList, err)
+
+	source := pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+		return pagination.ListFromArray(arrayListTestLetters, args), nil
+	})
+	_, err = pagination.ListItemFromSource(source, "Z", context.Background())
+	assert.Equal(t, pagination.ErrItemNotInList, err)
+}
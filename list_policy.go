@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"fmt"
+	"time"
+)
+
+// ListPolicy limits the pages of a list
+type ListPolicy struct {
+	// DefaultPageSize is the number of items of a page when neither first nor
+	// last is given, MaxPageSize if 0, and all items if both are 0
+	DefaultPageSize int `json:"defaultPageSize"`
+	// MaxPageSize is the maximum value of first and last, unlimited if 0
+	MaxPageSize int `json:"maxPageSize"`
+	// Timeout bounds the time spent loading a page, unlimited if 0, see `Load`
+	Timeout time.Duration `json:"timeout"`
+	// TokenSecret is the key signing AIP-158 page tokens, see `PageArguments`
+	TokenSecret []byte `json:"-"`
+}
+
+// ErrNegativePageSize is returned for requests with a negative page size
+var ErrNegativePageSize = &ListError{Code: "INVALID_ARGUMENT", Message: "Negative page size"}
+
+// Apply returns the list arguments with the policy defaults, or an error if
+// they exceed the policy limits or are negative, -1 meaning not given
+func (p ListPolicy) Apply(args ListArguments) (ListArguments, error) {
+	for _, size := range []int{args.First, args.Last} {
+		if size < -1 {
+			return args, ErrNegativePageSize
+		}
+	}
+	if p.MaxPageSize > 0 {
+		for _, size := range []int{args.First, args.Last} {
+			if size > p.MaxPageSize {
+				return args, &ListError{
+					Code:    "PAGE_SIZE_EXCEEDED",
+					Message: fmt.Sprintf("Page size %v exceeds maximum %v", size, p.MaxPageSize),
+				}
+			}
+		}
+	}
+	size := p.DefaultPageSize
+	if size == 0 {
+		size = p.MaxPageSize
+	}
+	if args.First == -1 && args.Last == -1 && size > 0 {
+		if args.Before != "" && args.After == "" {
+			args.Last = size
+		} else {
+			args.First = size
+		}
+	}
+	return args, nil
+}
+
+// PageSize returns the maximum number of items of a page, the first or last
+// argument if given, or the policy default. It returns -1 for all items.
+func (p ListPolicy) PageSize(args ListArguments) int {
+	size := -1
+	for _, s := range []int{args.First, args.Last} {
+		if s != -1 && (size == -1 || s < size) {
+			size = s
+		}
+	}
+	if size == -1 && p.DefaultPageSize > 0 {
+		size = p.DefaultPageSize
+	}
+	if p.MaxPageSize > 0 && (size == -1 || size > p.MaxPageSize) {
+		size = p.MaxPageSize
+	}
+	return size
+}
@@ -0,0 +1,56 @@
+package pagination_test
+
+import (
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPolicy_Apply(t *testing.T) {
+	policy := pagination.ListPolicy{DefaultPageSize: 10, MaxPageSize: 50}
+
+	args, err := policy.Apply(pagination.NewListArguments(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, args.First)
+	assert.Equal(t, -1, args.Last)
+
+	args, err = policy.Apply(pagination.NewListArguments(map[string]interface{}{
+		"before": string(pagination.OffsetToCursor(20)),
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, args.First)
+	assert.Equal(t, 10, args.Last)
+
+	args, err = policy.Apply(pagination.NewListArguments(map[string]interface{}{"last": 50}))
+	assert.NoError(t, err)
+	assert.Equal(t, 50, args.Last)
+
+	_, err = policy.Apply(pagination.NewListArguments(map[string]interface{}{"first": 51}))
+	assert.EqualError(t, err, "Page size 51 exceeds maximum 50")
+	assert.Equal(t, map[string]interface{}{"code": "PAGE_SIZE_EXCEEDED"}, err.(*pagination.ListError).Extensions())
+
+	_, err = policy.Apply(pagination.NewListArguments(map[string]interface{}{"last": -5}))
+	assert.Equal(t, pagination.ErrNegativePageSize, err)
+
+	args, err = pagination.ListPolicy{}.Apply(pagination.NewListArguments(map[string]interface{}{"first": 1000}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, args.First)
+
+	// pages are bounded by the maximum size without default
+	args, err = pagination.ListPolicy{MaxPageSize: 50}.Apply(pagination.NewListArguments(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 50, args.First)
+	args, err = pagination.ListPolicy{}.Apply(pagination.NewListArguments(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, args.First)
+}
+
+func TestListPolicy_PageSize(t *testing.T) {
+	policy := pagination.ListPolicy{DefaultPageSize: 10, MaxPageSize: 50}
+	assert.Equal(t, 10, policy.PageSize(pagination.NewListArguments(nil)))
+	assert.Equal(t, 3, policy.PageSize(pagination.NewListArguments(map[string]interface{}{"first": 5, "last": 3})))
+	assert.Equal(t, 50, policy.PageSize(pagination.NewListArguments(map[string]interface{}{"first": 100})))
+	assert.Equal(t, -1, pagination.ListPolicy{}.PageSize(pagination.NewListArguments(nil)))
+	assert.Equal(t, 50, pagination.ListPolicy{MaxPageSize: 50}.PageSize(pagination.NewListArguments(nil)))
+}
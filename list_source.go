@@ -0,0 +1,43 @@
+package pagination
+
+import (
+	"context"
+)
+
+// ListSource loads the window of a list described by list arguments.
+// Sources stop loading when ctx is done, returning `ContextError(ctx)`, and
+// may return the items loaded so far along with the error.
+type ListSource interface {
+	List(args ListArguments, ctx context.Context) (*List, error)
+}
+
+// ListSourceFn is an adapter to use ordinary functions as ListSource
+type ListSourceFn func(args ListArguments, ctx context.Context) (*List, error)
+
+// List implements ListSource
+func (fn ListSourceFn) List(args ListArguments, ctx context.Context) (*List, error) {
+	return fn(args, ctx)
+}
+
+// CursorLocator is implemented by list sources which can find the cursor of an
+// object without loading the whole list
+type CursorLocator interface {
+	// CursorFor returns the cursor of object, or an empty cursor if it is not in the list
+	CursorFor(object interface{}, ctx context.Context) (ListCursor, error)
+}
+
+// ArrayListSource is a ListSource over a static array, see `ListFromArray`
+type ArrayListSource []interface{}
+
+// List implements ListSource
+func (s ArrayListSource) List(args ListArguments, ctx context.Context) (*List, error) {
+	if err := ContextError(ctx); err != nil {
+		return nil, err
+	}
+	return ListFromArray(s, args), nil
+}
+
+// CursorFor implements CursorLocator
+func (s ArrayListSource) CursorFor(object interface{}, ctx context.Context) (ListCursor, error) {
+	return CursorForObjectInList(s, object), nil
+}
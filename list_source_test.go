@@ -0,0 +1,30 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayListSource_ListsLikeListFromArray(t *testing.T) {
+	args := pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+		"after": "YXJyYXljb25uZWN0aW9uOjA=",
+	})
+	list, err := pagination.ArrayListSource(arrayListTestLetters).List(args, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.ListFromArray(arrayListTestLetters, args), list)
+}
+
+func TestArrayListSource_CursorFor(t *testing.T) {
+	source := pagination.ArrayListSource(arrayListTestLetters)
+	cursor, err := source.CursorFor("B", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.OffsetToCursor(1), cursor)
+
+	cursor, err = source.CursorFor("Z", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.ListCursor(""), cursor)
+}
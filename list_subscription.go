@@ -0,0 +1,369 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ListPatchOp is the kind of change described by a ListPatch
+type ListPatchOp string
+
+// List patch operations
+const (
+	ListPatchInsert     ListPatchOp = "INSERT"
+	ListPatchRemove     ListPatchOp = "REMOVE"
+	ListPatchUpdate     ListPatchOp = "UPDATE"
+	ListPatchTotalCount ListPatchOp = "TOTAL_COUNT"
+)
+
+// ListPatch is an incremental change of the window of a list a client is looking at.
+// Inserted items come with their cursor and the cursor of the item they follow
+// (empty when inserted first), removed items with the cursor they had.
+type ListPatch struct {
+	Op          ListPatchOp `json:"op"`
+	Cursor      ListCursor  `json:"cursor"`
+	AfterCursor ListCursor  `json:"afterCursor"`
+	Item        interface{} `json:"item"`
+	TotalCount  int         `json:"totalCount"`
+}
+
+// ItemKeyFn returns a key identifying an item across list windows
+type ItemKeyFn func(item interface{}) interface{}
+
+// ItemCursorFn returns the cursor of the item at index in the items of a list
+type ItemCursorFn func(list *List, index int) ListCursor
+
+// OffsetItemCursor is the ItemCursorFn of lists using offsets as cursors, see `ListFromArray`
+func OffsetItemCursor(list *List, index int) ListCursor {
+	return OffsetToCursor(GetOffsetWithDefault(list.PageInfo.StartCursor, 0) + index)
+}
+
+/*
+The common list patch operation type used by all list subscriptions.
+*/
+var listPatchOpType = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "ListPatchOp",
+	Description: "Kind of change of a list.",
+	Values: graphql.EnumValueConfigMap{
+		"INSERT": &graphql.EnumValueConfig{
+			Value:       ListPatchInsert,
+			Description: "An item was inserted.",
+		},
+		"REMOVE": &graphql.EnumValueConfig{
+			Value:       ListPatchRemove,
+			Description: "An item was removed.",
+		},
+		"UPDATE": &graphql.EnumValueConfig{
+			Value:       ListPatchUpdate,
+			Description: "An item was updated.",
+		},
+		"TOTAL_COUNT": &graphql.EnumValueConfig{
+			Value:       ListPatchTotalCount,
+			Description: "The count of all list items changed.",
+		},
+	},
+})
+
+// ListPatchType describes a change of the list, see `ListPatch`. It is built
+// on first use.
+func (d *GraphQLListDefinitions) ListPatchType() *graphql.Object {
+	return d.lazyType("ListPatch", func(name string, itemType graphql.Output) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        name + "ListPatch",
+			Description: "A change of the list.",
+
+			Fields: graphql.Fields{
+				"op": &graphql.Field{
+					Type:        graphql.NewNonNull(listPatchOpType),
+					Description: "Kind of change.",
+				},
+				"cursor": &graphql.Field{
+					Type:        graphql.String,
+					Description: "The cursor of the inserted, removed or updated item.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if patch, ok := p.Source.(*ListPatch); ok && patch.Cursor != "" {
+							return patch.Cursor, nil
+						}
+						return nil, nil
+					},
+				},
+				"afterCursor": &graphql.Field{
+					Type:        graphql.String,
+					Description: "The cursor of the item an inserted item follows, null when inserted first.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if patch, ok := p.Source.(*ListPatch); ok && patch.AfterCursor != "" {
+							return patch.AfterCursor, nil
+						}
+						return nil, nil
+					},
+				},
+				"item": &graphql.Field{
+					Type:        itemType,
+					Description: "The inserted or updated item.",
+				},
+				"totalCount": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "The new count of all list items.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if patch, ok := p.Source.(*ListPatch); ok && patch.Op == ListPatchTotalCount {
+							return patch.TotalCount, nil
+						}
+						return nil, nil
+					},
+				},
+			},
+		})
+	})
+}
+
+// listWindow is a window of a list as last sent to a client
+type listWindow struct {
+	list         *List
+	keys         []interface{}
+	cursors      []ListCursor
+	fingerprints []string
+}
+
+func newListWindow(list *List, itemKey ItemKeyFn, itemCursor ItemCursorFn) *listWindow {
+	w := &listWindow{
+		list:         list,
+		keys:         make([]interface{}, len(list.Items)),
+		cursors:      make([]ListCursor, len(list.Items)),
+		fingerprints: make([]string, len(list.Items)),
+	}
+	for i, item := range list.Items {
+		w.keys[i] = itemKey(item)
+		w.cursors[i] = itemCursor(list, i)
+		// items may be mutated in place, so their content is recorded
+		b, err := json.Marshal(item)
+		if err != nil {
+			b = []byte(fmt.Sprintf("%#v", item))
+		}
+		w.fingerprints[i] = string(b)
+	}
+	return w
+}
+
+// DiffLists returns the patches turning the prev window of a list into next.
+// Items are matched with itemKey (identity by default), and their cursors are
+// given by itemCursor (`OffsetItemCursor` by default). Matched items whose
+// JSON encoding differ are updated, and matched items whose cursor changed or
+// which were reordered are removed and inserted again, removals coming first.
+func DiffLists(prev *List, next *List, itemKey ItemKeyFn, itemCursor ItemCursorFn) []*ListPatch {
+	if itemKey == nil {
+		itemKey = valueKey
+	}
+	if itemCursor == nil {
+		itemCursor = OffsetItemCursor
+	}
+	return diffListWindows(newListWindow(prev, itemKey, itemCursor), newListWindow(next, itemKey, itemCursor))
+}
+
+func diffListWindows(prev *listWindow, next *listWindow) []*ListPatch {
+	patches := []*ListPatch{}
+
+	prevIndexes := map[interface{}]int{}
+	for i, key := range prev.keys {
+		prevIndexes[key] = i
+	}
+	// items kept in place are those in both windows with the same cursor,
+	// keeping their order; others are removed and inserted again
+	matched := []int{}
+	for i, key := range next.keys {
+		if prevIndex, ok := prevIndexes[key]; ok && prev.cursors[prevIndex] == next.cursors[i] {
+			matched = append(matched, i)
+		}
+	}
+	kept := map[interface{}]bool{}
+	for _, i := range longestIncreasingRun(matched, func(i int) int { return prevIndexes[next.keys[i]] }) {
+		kept[next.keys[i]] = true
+	}
+
+	for i, key := range prev.keys {
+		if !kept[key] {
+			patches = append(patches, &ListPatch{
+				Op:     ListPatchRemove,
+				Cursor: prev.cursors[i],
+			})
+		}
+	}
+	for i, key := range next.keys {
+		switch {
+		case !kept[key]:
+			patch := &ListPatch{
+				Op:     ListPatchInsert,
+				Cursor: next.cursors[i],
+				Item:   next.list.Items[i],
+			}
+			if i > 0 {
+				patch.AfterCursor = next.cursors[i-1]
+			}
+			patches = append(patches, patch)
+		case prev.fingerprints[prevIndexes[key]] != next.fingerprints[i]:
+			patches = append(patches, &ListPatch{
+				Op:     ListPatchUpdate,
+				Cursor: next.cursors[i],
+				Item:   next.list.Items[i],
+			})
+		}
+	}
+
+	if prev.list.TotalCount != next.list.TotalCount {
+		patches = append(patches, &ListPatch{
+			Op:         ListPatchTotalCount,
+			TotalCount: next.list.TotalCount,
+		})
+	}
+	return patches
+}
+
+// longestIncreasingRun returns the longest subsequence of values whose ranks
+// increase, i.e. the most items which can stay in place when a list is reordered
+func longestIncreasingRun(values []int, rank func(value int) int) []int {
+	// tails[k] is the index of the smallest last value of runs of length k+1
+	tails := []int{}
+	parents := make([]int, len(values))
+	for i, value := range values {
+		k := sort.Search(len(tails), func(k int) bool {
+			return rank(values[tails[k]]) >= rank(value)
+		})
+		parents[i] = -1
+		if k > 0 {
+			parents[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	run := make([]int, len(tails))
+	if len(tails) == 0 {
+		return run
+	}
+	for k, i := len(tails)-1, tails[len(tails)-1]; k >= 0; k, i = k-1, parents[i] {
+		run[k] = values[i]
+	}
+	return run
+}
+
+// ListSubscriptionConfig is the configuration of a list subscription
+type ListSubscriptionConfig struct {
+	List *GraphQLListDefinitions
+	// Args are additional arguments of the field, `ListArgs` are always added
+	Args graphql.FieldConfigArgument
+	// Source returns the list source the client is subscribing to, it is
+	// called each time the window is loaded
+	Source func(p graphql.ResolveParams) (ListSource, error)
+	Events EventSource
+	// Topic returns the topic whose events signal changes of the list
+	Topic      func(p graphql.ResolveParams) string
+	ItemKey    ItemKeyFn
+	ItemCursor ItemCursorFn
+}
+
+// ListSubscription returns a subscription field sending patches of the window
+// of a list described by the list arguments.
+// Events published on the topic are only notifications: the window is loaded
+// again from the source after each of them, and the differences with the
+// previous window are sent as a list of patches.
+// The list policy applies to the arguments, and items the viewer may not
+// access are filtered out of the source, see `AuthorizedSource`.
+func ListSubscription(config ListSubscriptionConfig) *graphql.Field {
+	itemKey := config.ItemKey
+	if itemKey == nil {
+		itemKey = valueKey
+	}
+	itemCursor := config.ItemCursor
+	if itemCursor == nil {
+		itemCursor = OffsetItemCursor
+	}
+	args := graphql.FieldConfigArgument{}
+	for argName, argConfig := range config.Args {
+		args[argName] = argConfig
+	}
+
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(config.List.ListPatchType()))),
+		Args: NewListArgs(args),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if err, ok := p.Source.(error); ok {
+				return nil, err
+			}
+			return p.Source, nil
+		},
+		Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+			listArgs, err := config.List.ListArguments(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			parent := p.Context
+			if parent == nil {
+				parent = context.Background()
+			}
+			// the subscription ends with the context, or when loading the
+			// initial window fails
+			ctx, cancel := context.WithCancel(parent)
+			load := func() (*listWindow, error) {
+				source, err := config.Source(p)
+				if err != nil {
+					return nil, err
+				}
+				if source, err = config.List.AuthorizedSource(source, p.Info, ctx); err != nil {
+					return nil, err
+				}
+				list, err := source.List(listArgs, ctx)
+				if err != nil {
+					return nil, err
+				}
+				return newListWindow(list, itemKey, itemCursor), nil
+			}
+
+			// subscribe before loading the window, so that no change is missed
+			events := config.Events.Subscribe(ctx, config.Topic(p))
+			window, err := load()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+
+			patches := make(chan interface{})
+			go func() {
+				defer cancel()
+				defer close(patches)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case _, ok := <-events:
+						if !ok {
+							return
+						}
+					}
+					var payload interface{}
+					next, err := load()
+					if err != nil {
+						payload = err
+					} else {
+						diff := diffListWindows(window, next)
+						window = next
+						if len(diff) == 0 {
+							continue
+						}
+						payload = diff
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case patches <- payload:
+					}
+				}
+			}()
+			return patches, nil
+		},
+	}
+}
@@ -0,0 +1,359 @@
+package pagination_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLists_ReturnsPatches(t *testing.T) {
+	prev := pagination.ListFromArray([]interface{}{"A", "B", "C", "D"}, pagination.NewListArguments(map[string]interface{}{
+		"first": 3,
+	}))
+	next := pagination.ListFromArray([]interface{}{"Z", "A", "C", "D"}, pagination.NewListArguments(map[string]interface{}{
+		"first": 3,
+	}))
+
+	// A moved from cursor 0 to 1
+	patches := pagination.DiffLists(prev, next, nil, nil)
+	assert.Equal(t, []*pagination.ListPatch{
+		{
+			Op:     pagination.ListPatchRemove,
+			Cursor: pagination.OffsetToCursor(0),
+		},
+		{
+			Op:     pagination.ListPatchRemove,
+			Cursor: pagination.OffsetToCursor(1),
+		},
+		{
+			Op:     pagination.ListPatchInsert,
+			Cursor: pagination.OffsetToCursor(0),
+			Item:   "Z",
+		},
+		{
+			Op:          pagination.ListPatchInsert,
+			Cursor:      pagination.OffsetToCursor(1),
+			AfterCursor: pagination.OffsetToCursor(0),
+			Item:        "A",
+		},
+	}, patches)
+}
+
+func TestDiffLists_MovesReorderedItems(t *testing.T) {
+	// cursors of keyed items do not change with their position
+	keyCursor := func(list *pagination.List, index int) pagination.ListCursor {
+		return pagination.ListCursor(list.Items[index].(string))
+	}
+	prev := pagination.ListFromArray([]interface{}{"A", "B", "C"}, pagination.NewListArguments(nil))
+	next := pagination.ListFromArray([]interface{}{"C", "A", "B"}, pagination.NewListArguments(nil))
+
+	patches := pagination.DiffLists(prev, next, nil, keyCursor)
+	assert.Equal(t, []*pagination.ListPatch{
+		{
+			Op:     pagination.ListPatchRemove,
+			Cursor: "C",
+		},
+		{
+			Op:     pagination.ListPatchInsert,
+			Cursor: "C",
+			Item:   "C",
+		},
+	}, patches)
+
+	// offset cursors of all items changed
+	patches = pagination.DiffLists(prev, next, nil, nil)
+	assert.Len(t, patches, 6)
+}
+
+func TestDiffLists_ReinsertsItemsShiftedByFrontInserts(t *testing.T) {
+	prev := pagination.ListFromArray([]interface{}{"A", "B"}, pagination.NewListArguments(nil))
+	next := pagination.ListFromArray([]interface{}{"Z", "A", "B"}, pagination.NewListArguments(nil))
+
+	patches := pagination.DiffLists(prev, next, nil, nil)
+	assert.Equal(t, []*pagination.ListPatch{
+		{Op: pagination.ListPatchRemove, Cursor: pagination.OffsetToCursor(0)},
+		{Op: pagination.ListPatchRemove, Cursor: pagination.OffsetToCursor(1)},
+		{Op: pagination.ListPatchInsert, Cursor: pagination.OffsetToCursor(0), Item: "Z"},
+		{Op: pagination.ListPatchInsert, Cursor: pagination.OffsetToCursor(1), AfterCursor: pagination.OffsetToCursor(0), Item: "A"},
+		{Op: pagination.ListPatchInsert, Cursor: pagination.OffsetToCursor(2), AfterCursor: pagination.OffsetToCursor(1), Item: "B"},
+		{Op: pagination.ListPatchTotalCount, TotalCount: 3},
+	}, patches)
+}
+
+func TestDiffLists_DetectsUpdatesAndCountChanges(t *testing.T) {
+	dan := &user{ID: 1, Name: "Dan"}
+	prev := pagination.ListFromArray([]interface{}{dan, &user{ID: 2, Name: "Nick"}}, pagination.NewListArguments(nil))
+
+	nick := &user{ID: 2, Name: "Nicolas"}
+	lee := &user{ID: 3, Name: "Lee"}
+	next := pagination.ListFromArray([]interface{}{dan, nick, lee}, pagination.NewListArguments(nil))
+
+	patches := pagination.DiffLists(prev, next, func(item interface{}) interface{} {
+		return item.(*user).ID
+	}, nil)
+	assert.Equal(t, []*pagination.ListPatch{
+		{
+			Op:     pagination.ListPatchUpdate,
+			Cursor: pagination.OffsetToCursor(1),
+			Item:   nick,
+		},
+		{
+			Op:          pagination.ListPatchInsert,
+			Cursor:      pagination.OffsetToCursor(2),
+			AfterCursor: pagination.OffsetToCursor(1),
+			Item:        lee,
+		},
+		{
+			Op:         pagination.ListPatchTotalCount,
+			TotalCount: 3,
+		},
+	}, patches)
+}
+
+func TestListSubscription_SendsPatches(t *testing.T) {
+	var mu sync.Mutex
+	letters := []interface{}{"A", "B", "C"}
+	loads := 0
+	bus := pagination.NewEventBus(1)
+
+	letterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Letter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+	letterListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "Letter",
+		ItemType: letterType,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: letterListDef.ListType,
+				},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"letters": pagination.ListSubscription(pagination.ListSubscriptionConfig{
+					List: letterListDef,
+					Source: func(p graphql.ResolveParams) (pagination.ListSource, error) {
+						return pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+							mu.Lock()
+							defer mu.Unlock()
+							loads++
+							return pagination.ListFromArray(letters, args), nil
+						}), nil
+					},
+					Events: bus,
+					Topic: func(p graphql.ResolveParams) string {
+						return "letters"
+					},
+				}),
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := graphql.Subscribe(graphql.Params{
+		Schema: schema,
+		RequestString: `subscription {
+          letters(first: 2) {
+            op
+            cursor
+            afterCursor
+            item { value }
+            totalCount
+          }
+        }`,
+		Context: ctx,
+	})
+	// wait for the initial window to be loaded
+	for {
+		mu.Lock()
+		loaded := loads > 0
+		mu.Unlock()
+		if loaded {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	letters = []interface{}{"A", "Z", "B", "C"}
+	mu.Unlock()
+	bus.Publish("letters", nil)
+
+	result := <-results
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"letters": []interface{}{
+			map[string]interface{}{
+				"op":          "REMOVE",
+				"cursor":      string(pagination.OffsetToCursor(1)),
+				"afterCursor": nil,
+				"item":        nil,
+				"totalCount":  nil,
+			},
+			map[string]interface{}{
+				"op":          "INSERT",
+				"cursor":      string(pagination.OffsetToCursor(1)),
+				"afterCursor": string(pagination.OffsetToCursor(0)),
+				"item":        map[string]interface{}{"value": "Z"},
+				"totalCount":  nil,
+			},
+			map[string]interface{}{
+				"op":          "TOTAL_COUNT",
+				"cursor":      nil,
+				"afterCursor": nil,
+				"item":        nil,
+				"totalCount":  4,
+			},
+		},
+	}, result.Data)
+
+	cancel()
+	for range results {
+	}
+}
+
+func TestListSubscription_AppliesListPolicyAndAuthorization(t *testing.T) {
+	var mu sync.Mutex
+	bus := pagination.NewEventBus(1)
+	letters := []interface{}{"A", "B", "C"}
+	failing := false
+
+	letterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Letter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+	letterListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "Letter",
+		ItemType: letterType,
+		Authorize: func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+			return obj != "B", nil
+		},
+		Policy: pagination.ListPolicy{MaxPageSize: 3},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: letterListDef.ListType,
+				},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"letters": pagination.ListSubscription(pagination.ListSubscriptionConfig{
+					List: letterListDef,
+					Source: func(p graphql.ResolveParams) (pagination.ListSource, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						if failing {
+							return nil, errors.New("Source unavailable")
+						}
+						return pagination.ArrayListSource(letters), nil
+					},
+					Events: bus,
+					Topic: func(p graphql.ResolveParams) string {
+						return "letters"
+					},
+				}),
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscribe := func(query string) *graphql.Result {
+		return <-graphql.Subscribe(graphql.Params{
+			Schema:        schema,
+			RequestString: query,
+			Context:       context.Background(),
+		})
+	}
+
+	result := subscribe(`subscription { letters(first: 4) { op } }`)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Page size 4 exceeds maximum 3", result.Errors[0].Message)
+	}
+
+	// failed subscriptions do not leak their events subscription
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	result = subscribe(`subscription { letters(first: 3) { op } }`)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Source unavailable", result.Errors[0].Message)
+	}
+	assert.Eventually(t, func() bool {
+		return bus.Subscribers("letters") == 0
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { letters(first: 3) { op cursor item { value } } }`,
+		Context:       ctx,
+	})
+	assert.Eventually(t, func() bool {
+		return bus.Subscribers("letters") == 1
+	}, time.Second, time.Millisecond)
+	// B stays hidden, and cursors do not account for it
+	mu.Lock()
+	letters = []interface{}{"A", "B", "D"}
+	mu.Unlock()
+	bus.Publish("letters", nil)
+	result = <-results
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"letters": []interface{}{
+			map[string]interface{}{
+				"op":     "REMOVE",
+				"cursor": string(pagination.OffsetToCursor(1)),
+				"item":   nil,
+			},
+			map[string]interface{}{
+				"op":     "INSERT",
+				"cursor": string(pagination.OffsetToCursor(1)),
+				"item":   map[string]interface{}{"value": "D"},
+			},
+		},
+	}, result.Data)
+
+	cancel()
+	for range results {
+	}
+}
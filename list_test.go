@@ -115,3 +115,32 @@ func TestListDefinition_IncludesListAndItemFields(t *testing.T) {
 	})
 	assert.EqualValues(t, expected, result)
 }
+
+func TestListDefinitions_BuildsFeatureTypesOnUse(t *testing.T) {
+	shipType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "LazyShip",
+		Fields: graphql.Fields{"name": &graphql.Field{Type: graphql.String}},
+	})
+	shipListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "LazyShip",
+		ItemType: shipType,
+	})
+	// lists only reserve the names of the features they use
+	_, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"ships": &graphql.Field{Type: shipListDef.ListType},
+				"patch": &graphql.Field{Type: graphql.NewObject(graphql.ObjectConfig{
+					Name:   "LazyShipListPatch",
+					Fields: graphql.Fields{"id": &graphql.Field{Type: graphql.ID}},
+				})},
+			},
+		}),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "LazyShipListItem", shipListDef.ListItemType().Name())
+	assert.Same(t, shipListDef.ListItemType(), shipListDef.ListItemType())
+	assert.Equal(t, "LazyShipChangeList", shipListDef.ChangeListType().Name())
+}
@@ -0,0 +1,278 @@
+package pagination
+
+import (
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const mergePrefix = "merge:"
+
+// ItemLessFn reports whether item a is ordered before item b
+type ItemLessFn func(a, b interface{}) bool
+
+// MergeSource is a sorted list source merged by `MergeListSources`
+type MergeSource struct {
+	Source ListSource
+	// ItemCursor returns the cursors of the items of the source, `OffsetItemCursor` by default
+	ItemCursor ItemCursorFn
+}
+
+// mergeCursor is the position of an item in a merged list: for each source,
+// the cursor of the last item ordered before it or being it, and the cursor
+// of the first item ordered after it or being it.
+// Empty cursors mean the beginning or the end of a source.
+type mergeCursor struct {
+	After  []ListCursor `json:"a"`
+	Before []ListCursor `json:"b"`
+}
+
+func mergeCursorToCursor(c *mergeCursor) ListCursor {
+	b, _ := json.Marshal(c)
+	return ListCursor(base64.StdEncoding.EncodeToString(append([]byte(mergePrefix), b...)))
+}
+
+func cursorToMergeCursor(cursor ListCursor, sources int) (*mergeCursor, error) {
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), mergePrefix) {
+		return nil, errors.New("Invalid cursor")
+	}
+	c := &mergeCursor{}
+	if err := json.Unmarshal(b[len(mergePrefix):], c); err != nil || len(c.After) != sources || len(c.Before) != sources {
+		return nil, errors.New("Invalid cursor")
+	}
+	return c, nil
+}
+
+// MergedListSource is a ListSource merging several sorted list sources, see `MergeListSources`
+type MergedListSource struct {
+	less    ItemLessFn
+	sources []MergeSource
+}
+
+// MergeListSources returns a list source merging sources sorted by less, e.g.
+// shards of the same table. Its cursors remember the position in each source,
+// and the total count is the sum of the total counts of the sources.
+// Items ordered the same way are ordered by source.
+// Each page loads at most `first` (or `last`) plus one items of each source,
+// and fails if any of them fails or doesn't respond before ctx is done.
+func MergeListSources(less ItemLessFn, sources ...MergeSource) *MergedListSource {
+	return &MergedListSource{
+		less:    less,
+		sources: sources,
+	}
+}
+
+// mergeHead is the next item of a source to merge
+type mergeHead struct {
+	source int
+	index  int
+}
+
+// mergeHeap orders the heads of the sources, in reverse when merging backwards
+type mergeHeap struct {
+	heads    []mergeHead
+	pages    [][]interface{}
+	less     ItemLessFn
+	backward bool
+}
+
+func (h *mergeHeap) Len() int { return len(h.heads) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.heads[i], h.heads[j]
+	if h.backward {
+		a, b = b, a
+	}
+	itemA, itemB := h.pages[a.source][a.index], h.pages[b.source][b.index]
+	if h.less(itemA, itemB) {
+		return true
+	}
+	if h.less(itemB, itemA) {
+		return false
+	}
+	return a.source < b.source
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *mergeHeap) Push(x interface{}) { h.heads = append(h.heads, x.(mergeHead)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	head := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return head
+}
+
+// List implements ListSource
+func (m *MergedListSource) List(args ListArguments, ctx context.Context) (*List, error) {
+	n := len(m.sources)
+	after := make([]ListCursor, n)
+	before := make([]ListCursor, n)
+	if args.After != "" {
+		c, err := cursorToMergeCursor(args.After, n)
+		if err != nil {
+			return nil, err
+		}
+		after = c.After
+	}
+	if args.Before != "" {
+		c, err := cursorToMergeCursor(args.Before, n)
+		if err != nil {
+			return nil, err
+		}
+		before = c.Before
+	}
+
+	backward := args.First == -1 && args.Last != -1
+	limit := args.First
+	if backward {
+		limit = args.Last
+	}
+
+	// load the sources concurrently
+	pages := make([][]interface{}, n)
+	cursors := make([][]ListCursor, n)
+	errs := make([]error, n)
+	totalCount := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := range m.sources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if rec := recover(); rec != nil {
+					errs[i] = fmt.Errorf("List source %v panicked: %v", i, rec)
+				}
+			}()
+			sourceArgs := NewListArguments(nil)
+			sourceArgs.After, sourceArgs.Before = after[i], before[i]
+			if limit != -1 {
+				if backward {
+					sourceArgs.Last = limit + 1
+				} else {
+					sourceArgs.First = limit + 1
+				}
+			}
+			list, err := m.sources[i].Source.List(sourceArgs, ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			itemCursor := m.sources[i].ItemCursor
+			if itemCursor == nil {
+				itemCursor = OffsetItemCursor
+			}
+			pages[i] = list.Items
+			cursors[i] = make([]ListCursor, len(list.Items))
+			for j := range list.Items {
+				cursors[i][j] = itemCursor(list, j)
+			}
+			mu.Lock()
+			totalCount += list.TotalCount
+			mu.Unlock()
+		}(i)
+	}
+	loaded := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(loaded)
+	}()
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	// a partial merge would skip the items of late sources
+	select {
+	case <-loaded:
+	case <-done:
+		return nil, ContextError(ctx)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h := &mergeHeap{pages: pages, less: m.less, backward: backward}
+	for i, page := range pages {
+		if len(page) > 0 {
+			index := 0
+			if backward {
+				index = len(page) - 1
+			}
+			h.heads = append(h.heads, mergeHead{source: i, index: index})
+		}
+	}
+	heap.Init(h)
+
+	// positions of the sources, moved as items are merged
+	afterPositions := append([]ListCursor{}, after...)
+	beforePositions := append([]ListCursor{}, before...)
+	var items []interface{}
+	var itemCursors []*mergeCursor
+	for h.Len() > 0 && (limit == -1 || len(items) < limit) {
+		head := heap.Pop(h).(mergeHead)
+		cursor := cursors[head.source][head.index]
+		next := head.index + 1
+		if backward {
+			next = head.index - 1
+		}
+		if next >= 0 && next < len(pages[head.source]) {
+			heap.Push(h, mergeHead{source: head.source, index: next})
+		}
+
+		// the other sources are positioned at their next item to merge
+		positions := make([]ListCursor, n)
+		for i := range positions {
+			positions[i] = after[i]
+			if !backward {
+				positions[i] = before[i]
+			}
+		}
+		for _, other := range h.heads {
+			positions[other.source] = cursors[other.source][other.index]
+		}
+		positions[head.source] = cursor
+
+		c := &mergeCursor{}
+		if backward {
+			beforePositions[head.source] = cursor
+			c.After, c.Before = positions, append([]ListCursor{}, beforePositions...)
+		} else {
+			afterPositions[head.source] = cursor
+			c.After, c.Before = append([]ListCursor{}, afterPositions...), positions
+		}
+		items = append(items, pages[head.source][head.index])
+		itemCursors = append(itemCursors, c)
+	}
+
+	list := NewList()
+	list.TotalCount = totalCount
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+			itemCursors[i], itemCursors[j] = itemCursors[j], itemCursors[i]
+		}
+		list.PageInfo.HasPreviousPage = h.Len() > 0
+	} else {
+		list.PageInfo.HasNextPage = args.First != -1 && h.Len() > 0
+		if args.Last != -1 && len(items) > args.Last {
+			items = items[len(items)-args.Last:]
+			itemCursors = itemCursors[len(itemCursors)-args.Last:]
+			list.PageInfo.HasPreviousPage = true
+		}
+	}
+	if len(items) > 0 {
+		list.Items = items
+		list.PageInfo.StartCursor = mergeCursorToCursor(itemCursors[0])
+		list.PageInfo.EndCursor = mergeCursorToCursor(itemCursors[len(itemCursors)-1])
+	}
+	return list, nil
+}
@@ -0,0 +1,146 @@
+package pagination_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func lessInts(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+func newMergedInts() *pagination.MergedListSource {
+	ints := pagination.NewOrderedCollection(func(item interface{}) int {
+		return item.(int)
+	})
+	for _, i := range []int{2, 3, 8} {
+		ints.Put(i)
+	}
+	return pagination.MergeListSources(lessInts,
+		pagination.MergeSource{Source: pagination.ArrayListSource{1, 4, 5, 9}},
+		pagination.MergeSource{Source: ints, ItemCursor: pagination.OrderedItemCursor(func(item interface{}) int {
+			return item.(int)
+		})},
+		pagination.MergeSource{Source: pagination.ArrayListSource{}},
+		pagination.MergeSource{Source: pagination.ArrayListSource{6, 7}},
+	)
+}
+
+func TestMergeListSources_MergesAllItems(t *testing.T) {
+	list, err := newMergedInts().List(pagination.NewListArguments(nil), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9}, list.Items)
+	assert.Equal(t, 9, list.TotalCount)
+	assert.False(t, list.PageInfo.HasNextPage)
+	assert.False(t, list.PageInfo.HasPreviousPage)
+}
+
+func TestMergeListSources_PaginatesForwardsAndBackwards(t *testing.T) {
+	merged := newMergedInts()
+
+	var items []interface{}
+	var cursors []pagination.ListCursor
+	args := pagination.NewListArguments(map[string]interface{}{"first": 2})
+	for {
+		list, err := merged.List(args, context.Background())
+		assert.NoError(t, err)
+		items = append(items, list.Items...)
+		cursors = append(cursors, list.PageInfo.EndCursor)
+		if !list.PageInfo.HasNextPage {
+			break
+		}
+		args.After = list.PageInfo.EndCursor
+	}
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+	assert.Len(t, cursors, 5)
+
+	items = nil
+	args = pagination.NewListArguments(map[string]interface{}{"last": 4})
+	for {
+		list, err := merged.List(args, context.Background())
+		assert.NoError(t, err)
+		items = append(list.Items, items...)
+		if !list.PageInfo.HasPreviousPage {
+			break
+		}
+		args.Before = list.PageInfo.StartCursor
+	}
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+
+	// the end cursor of a page is the start of the next one in both directions
+	list, err := merged.List(pagination.NewListArguments(map[string]interface{}{
+		"last":   3,
+		"before": string(cursors[2]),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{3, 4, 5}, list.Items)
+	assert.True(t, list.PageInfo.HasPreviousPage)
+
+	list, err = merged.List(pagination.NewListArguments(map[string]interface{}{
+		"first":  5,
+		"after":  string(cursors[0]),
+		"before": string(cursors[3]),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{3, 4, 5, 6, 7}, list.Items)
+	assert.False(t, list.PageInfo.HasNextPage)
+
+	list, err = merged.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 4,
+		"last":  2,
+		"after": string(list.PageInfo.StartCursor),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{6, 7}, list.Items)
+	assert.True(t, list.PageInfo.HasNextPage)
+	assert.True(t, list.PageInfo.HasPreviousPage)
+}
+
+func TestMergeListSources_OrdersEqualItemsBySource(t *testing.T) {
+	merged := pagination.MergeListSources(func(a, b interface{}) bool {
+		return a.(*user).ID < b.(*user).ID
+	},
+		pagination.MergeSource{Source: pagination.ArrayListSource{&user{ID: 1, Name: "Dan"}, &user{ID: 2, Name: "Lee"}}},
+		pagination.MergeSource{Source: pagination.ArrayListSource{&user{ID: 1, Name: "Nick"}}},
+	)
+	list, err := merged.List(pagination.NewListArguments(map[string]interface{}{"first": 2}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{&user{ID: 1, Name: "Dan"}, &user{ID: 1, Name: "Nick"}}, list.Items)
+
+	list, err = merged.List(pagination.NewListArguments(map[string]interface{}{
+		"after": string(list.PageInfo.StartCursor),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{&user{ID: 1, Name: "Nick"}, &user{ID: 2, Name: "Lee"}}, list.Items)
+}
+
+func TestMergeListSources_ReturnsErrors(t *testing.T) {
+	merged := pagination.MergeListSources(lessInts,
+		pagination.MergeSource{Source: pagination.ArrayListSource{1}},
+		pagination.MergeSource{Source: pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+			return nil, errors.New("Shard unavailable")
+		})},
+	)
+	_, err := merged.List(pagination.NewListArguments(nil), context.Background())
+	assert.EqualError(t, err, "Shard unavailable")
+
+	_, err = merged.List(pagination.NewListArguments(map[string]interface{}{
+		"after": string(pagination.OffsetToCursor(1)),
+	}), context.Background())
+	assert.EqualError(t, err, "Invalid cursor")
+}
+
+func TestMergeListSources_RecoversPanics(t *testing.T) {
+	merged := pagination.MergeListSources(lessInts,
+		pagination.MergeSource{Source: pagination.ArrayListSource{1}},
+		pagination.MergeSource{Source: pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+			panic("shard crashed")
+		})},
+	)
+	_, err := merged.List(pagination.NewListArguments(nil), context.Background())
+	assert.EqualError(t, err, "List source 1 panicked: shard crashed")
+}
@@ -13,36 +13,59 @@ type MutationFn func(inputMap map[string]interface{}, info graphql.ResolveInfo,
 // A description of a mutation consumable by mutationWithClientMutationId
 // to create a GraphQLField for that mutation.
 // The inputFields and outputFields should not include `clientMutationId`,
-// as this will be provided automatically.
+// as this will be provided automatically. It is optional, as in the modern
+// Relay specification.
 // An input object will be created containing the input fields, and an
 // object will be created containing the output fields.
 // mutateAndGetPayload will receive an Object with a key for each
 // input field, and it should return an Object with a key for each
 // output field. It may return synchronously, or return a Promise.
+// When an IdempotencyStore is given, the payload of a mutation carrying a
+// `clientMutationId` is stored, and retries of the same mutation by the same
+// viewer replay it instead of calling mutateAndGetPayload again. Retries with
+// a different input fail with ErrClientMutationIDReused, and retries of a
+// running mutation with ErrMutationInProgress. Viewer is then required, and
+// the mutations of anonymous viewers, i.e. "", are not replayed.
+// When UserErrors is set, a `userErrors: [UserError!]!` field is added to the
+// payload, filled with the `UserError`s returned by mutateAndGetPayload.
+// Middleware wraps mutateAndGetPayload, the first one being the outermost,
+// and also runs around replays.
 type MutationConfig struct {
 	Name                string                            `json:"name"`
 	InputFields         graphql.InputObjectConfigFieldMap `json:"inputFields"`
 	OutputFields        graphql.Fields                    `json:"outputFields"`
 	MutateAndGetPayload MutationFn                        `json:"mutateAndGetPayload"`
+	IdempotencyStore    IdempotencyStore                  `json:"-"`
+	Viewer              ViewerFn                          `json:"-"`
+	UserErrors          bool                              `json:"userErrors"`
+	Middleware          []MutationMiddleware              `json:"-"`
 }
 
 // MutationWithClientMutationID returns a GraphQLField for the mutation described by the
 // provided MutationConfig.
 func MutationWithClientMutationID(config MutationConfig) *graphql.Field {
+	if config.IdempotencyStore != nil && config.Viewer == nil {
+		panic("Mutation " + config.Name + " has an IdempotencyStore but no Viewer")
+	}
 
 	augmentedInputFields := config.InputFields
 	if augmentedInputFields == nil {
 		augmentedInputFields = graphql.InputObjectConfigFieldMap{}
 	}
 	augmentedInputFields["clientMutationId"] = &graphql.InputObjectFieldConfig{
-		Type: graphql.NewNonNull(graphql.String),
+		Type: graphql.String,
 	}
 	augmentedOutputFields := config.OutputFields
 	if augmentedOutputFields == nil {
 		augmentedOutputFields = graphql.Fields{}
 	}
 	augmentedOutputFields["clientMutationId"] = &graphql.Field{
-		Type: graphql.NewNonNull(graphql.String),
+		Type: graphql.String,
+	}
+	if config.UserErrors {
+		augmentedOutputFields["userErrors"] = &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userErrorType))),
+		}
 	}
 
 	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
@@ -71,13 +94,74 @@ func MutationWithClientMutationID(config MutationConfig) *graphql.Field {
 					input = inputVal
 				}
 			}
-			payload, err := config.MutateAndGetPayload(input, p.Info, p.Context)
+			mutateAndGetPayload := config.MutateAndGetPayload
+			idempotencyKey, inputHash, replayed := "", "", false
+			viewer := ""
+			if config.IdempotencyStore != nil {
+				viewer = config.Viewer(p.Context)
+			}
+			// anonymous viewers would share payloads, their mutations are never replayed
+			if clientMutationID, ok := input["clientMutationId"].(string); ok && viewer != "" {
+				key := IdempotencyKey(viewer, config.Name, clientMutationID)
+				inputHash = IdempotencyInputHash(input)
+				// the replay is the innermost step, so that retries still go
+				// through the middleware, e.g. permission checks
+				mutateAndGetPayload = func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+					record, reserved := config.IdempotencyStore.Reserve(key)
+					if record != nil {
+						if record.InputHash != inputHash {
+							return nil, ErrClientMutationIDReused
+						}
+						replayed = true
+						payload := make(map[string]interface{}, len(record.Payload))
+						for k, v := range record.Payload {
+							payload[k] = v
+						}
+						return payload, nil
+					}
+					if !reserved {
+						return nil, ErrMutationInProgress
+					}
+					idempotencyKey = key
+					return config.MutateAndGetPayload(inputMap, info, ctx)
+				}
+				// the reservation is released unless the payload is stored,
+				// including when mutateAndGetPayload panics
+				defer func() {
+					if idempotencyKey != "" {
+						config.IdempotencyStore.Release(idempotencyKey)
+					}
+				}()
+			}
+			mutateAndGetPayload = ChainMutationMiddleware(mutateAndGetPayload, config.Middleware...)
+			payload, err := mutateAndGetPayload(input, p.Info, p.Context)
+			var userErrors UserErrors
 			if err != nil {
-				return nil, err
+				var ok bool
+				if userErrors, ok = AsUserErrors(err); !ok || !config.UserErrors {
+					return nil, err
+				}
+			}
+			if payload == nil {
+				payload = map[string]interface{}{}
+			}
+			if config.UserErrors {
+				if userErrors != nil {
+					payload["userErrors"] = userErrors
+				} else if _, ok := payload["userErrors"]; !ok {
+					payload["userErrors"] = UserErrors{}
+				}
 			}
 			if clientMutationID, ok := input["clientMutationId"]; ok {
 				payload["clientMutationId"] = clientMutationID
 			}
+			if idempotencyKey != "" && !replayed {
+				config.IdempotencyStore.Set(idempotencyKey, &IdempotencyRecord{
+					InputHash: inputHash,
+					Payload:   payload,
+				})
+				idempotencyKey = ""
+			}
 			return payload, nil
 		},
 	}
@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// MutationMiddleware wraps a MutationFn to run code around it, such as
+// permission checks, transactions or audit logging.
+// It may pass a derived context to next, or short-circuit the mutation by
+// returning an error without calling next.
+type MutationMiddleware func(next MutationFn) MutationFn
+
+// ChainMutationMiddleware wraps fn with the given middleware.
+// The first middleware is the outermost one, i.e. it runs first.
+func ChainMutationMiddleware(fn MutationFn, middleware ...MutationMiddleware) MutationFn {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		fn = middleware[i](fn)
+	}
+	return fn
+}
+
+// InputValidatorFn validates the value of an input field, nil if it was omitted
+type InputValidatorFn func(value interface{}) error
+
+// ValidateInput returns a middleware validating input fields before running the mutation.
+// Validation failures are returned as `UserErrors` pointing at the invalid fields,
+// with the `INVALID_INPUT` code unless the validator returned a `UserError` itself.
+func ValidateInput(validators map[string]InputValidatorFn) MutationMiddleware {
+	fieldNames := make([]string, 0, len(validators))
+	for fieldName := range validators {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	return func(next MutationFn) MutationFn {
+		return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			userErrors := UserErrors{}
+			for _, fieldName := range fieldNames {
+				err := validators[fieldName](inputMap[fieldName])
+				if err == nil {
+					continue
+				}
+				if errs, ok := AsUserErrors(err); ok {
+					for _, userError := range errs {
+						if userError.Field == nil {
+							// validators may return shared errors, which are left untouched
+							copied := *userError
+							copied.Field = []string{fieldName}
+							userError = &copied
+						}
+						userErrors = append(userErrors, userError)
+					}
+					continue
+				}
+				userErrors = append(userErrors, NewUserError([]string{fieldName}, err.Error(), "INVALID_INPUT"))
+			}
+			if len(userErrors) > 0 {
+				return nil, userErrors
+			}
+			return next(inputMap, info, ctx)
+		}
+	}
+}
+
+// RecoverPanic returns a middleware turning panics of the wrapped mutation into errors
+func RecoverPanic() MutationMiddleware {
+	return func(next MutationFn) MutationFn {
+		return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (payload map[string]interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					payload = nil
+					err = fmt.Errorf("Mutation %v panicked: %v", info.FieldName, r)
+				}
+			}()
+			return next(inputMap, info, ctx)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package pagination_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type middlewareTestKey struct{}
+
+func middlewareTestTrace(name string, trace *[]string) pagination.MutationMiddleware {
+	return func(next pagination.MutationFn) pagination.MutationFn {
+		return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			*trace = append(*trace, name+":before")
+			payload, err := next(inputMap, info, ctx)
+			*trace = append(*trace, name+":after")
+			return payload, err
+		}
+	}
+}
+
+func TestChainMutationMiddleware_RunsMiddlewareInOrder(t *testing.T) {
+	trace := []string{}
+	fn := pagination.ChainMutationMiddleware(
+		func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			trace = append(trace, "mutation")
+			return map[string]interface{}{}, nil
+		},
+		middlewareTestTrace("first", &trace),
+		middlewareTestTrace("second", &trace),
+	)
+	_, err := fn(nil, graphql.ResolveInfo{}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"first:before",
+		"second:before",
+		"mutation",
+		"second:after",
+		"first:after",
+	}, trace)
+}
+
+func TestChainMutationMiddleware_PropagatesContext(t *testing.T) {
+	withViewer := func(next pagination.MutationFn) pagination.MutationFn {
+		return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			return next(inputMap, info, context.WithValue(ctx, middlewareTestKey{}, "alice"))
+		}
+	}
+	fn := pagination.ChainMutationMiddleware(
+		func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"viewer": ctx.Value(middlewareTestKey{})}, nil
+		},
+		withViewer,
+	)
+	payload, err := fn(nil, graphql.ResolveInfo{}, context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", payload["viewer"])
+}
+
+func TestChainMutationMiddleware_ShortCircuitsOnError(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+	called := false
+	fn := pagination.ChainMutationMiddleware(
+		func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			called = true
+			return map[string]interface{}{}, nil
+		},
+		func(next pagination.MutationFn) pagination.MutationFn {
+			return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+				return nil, errForbidden
+			}
+		},
+	)
+	_, err := fn(nil, graphql.ResolveInfo{}, context.Background())
+	assert.Equal(t, errForbidden, err)
+	assert.False(t, called)
+}
+
+var middlewareTestSchema graphql.Schema
+
+func init() {
+	mutation := pagination.MutationWithClientMutationID(pagination.MutationConfig{
+		Name: "CreateUser",
+		InputFields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{
+				Type: graphql.String,
+			},
+			"age": &graphql.InputObjectFieldConfig{
+				Type: graphql.Int,
+			},
+		},
+		OutputFields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			if inputMap["name"] == "panic" {
+				panic("unexpected name")
+			}
+			return map[string]interface{}{
+				"name": inputMap["name"],
+			}, nil
+		},
+		UserErrors: true,
+		Middleware: []pagination.MutationMiddleware{
+			pagination.RecoverPanic(),
+			pagination.ValidateInput(map[string]pagination.InputValidatorFn{
+				"name": func(value interface{}) error {
+					if value == nil || value == "" {
+						return pagination.NewUserError(nil, "Name can't be blank", "BLANK")
+					}
+					return nil
+				},
+				"age": func(value interface{}) error {
+					if age, ok := value.(int); ok && age < 0 {
+						return errors.New("Age must be positive")
+					}
+					return nil
+				},
+			}),
+		},
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": mutation,
+		},
+	})
+	middlewareTestSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+}
+
+func TestValidateInput_ReturnsUserErrors(t *testing.T) {
+	query := `
+        mutation M {
+          createUser(input: {age: -1}) {
+            name
+            userErrors { field message code }
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"createUser": map[string]interface{}{
+				"name": nil,
+				"userErrors": []interface{}{
+					map[string]interface{}{
+						"field":   []interface{}{"age"},
+						"message": "Age must be positive",
+						"code":    "INVALID_INPUT",
+					},
+					map[string]interface{}{
+						"field":   []interface{}{"name"},
+						"message": "Name can't be blank",
+						"code":    "BLANK",
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        middlewareTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestValidateInput_RunsValidMutations(t *testing.T) {
+	query := `
+        mutation M {
+          createUser(input: {name: "Dan", age: 30}) {
+            name
+            userErrors { message }
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"createUser": map[string]interface{}{
+				"name":       "Dan",
+				"userErrors": []interface{}{},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        middlewareTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestRecoverPanic_ReturnsError(t *testing.T) {
+	query := `
+        mutation M {
+          createUser(input: {name: "panic"}) {
+            name
+          }
+        }
+      `
+	result := graphql.Do(graphql.Params{
+		Schema:        middlewareTestSchema,
+		RequestString: query,
+	})
+	assert.EqualValues(t, map[string]interface{}{"createUser": nil}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Mutation createUser panicked: unexpected name", result.Errors[0].Message)
+	}
+}
+
+func TestValidateInput_LeavesValidatorErrorsUntouched(t *testing.T) {
+	blank := pagination.NewUserError(nil, "Can't be blank", "BLANK")
+	notBlank := func(value interface{}) error {
+		if value == nil {
+			return blank
+		}
+		return nil
+	}
+	mutate := pagination.ChainMutationMiddleware(
+		func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			return nil, nil
+		},
+		pagination.ValidateInput(map[string]pagination.InputValidatorFn{
+			"firstName": notBlank,
+			"lastName":  notBlank,
+		}),
+	)
+	_, err := mutate(map[string]interface{}{}, graphql.ResolveInfo{}, context.Background())
+	userErrors, _ := pagination.AsUserErrors(err)
+	if assert.Len(t, userErrors, 2) {
+		assert.Equal(t, []string{"firstName"}, userErrors[0].Field)
+		assert.Equal(t, []string{"lastName"}, userErrors[1].Field)
+	}
+	assert.Nil(t, blank.Field)
+}
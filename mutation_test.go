@@ -3,6 +3,8 @@ package pagination_test
 import (
 	"errors"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/graphql-go/graphql/language/location"
 	"github.com/graphql-go/graphql/testutil"
 	"github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAsyncDataMutation(resultChan *chan int) {
@@ -199,12 +202,9 @@ func TestMutation_IntrospectsCorrectly_ContainsCorrectInput(t *testing.T) {
 					map[string]interface{}{
 						"name": "clientMutationId",
 						"type": map[string]interface{}{
-							"name": nil,
-							"kind": "NON_NULL",
-							"ofType": map[string]interface{}{
-								"name": "String",
-								"kind": "SCALAR",
-							},
+							"name":   "String",
+							"kind":   "SCALAR",
+							"ofType": nil,
 						},
 					},
 				},
@@ -254,12 +254,9 @@ func TestMutation_IntrospectsCorrectly_ContainsCorrectPayload(t *testing.T) {
 					map[string]interface{}{
 						"name": "clientMutationId",
 						"type": map[string]interface{}{
-							"name": nil,
-							"kind": "NON_NULL",
-							"ofType": map[string]interface{}{
-								"name": "String",
-								"kind": "SCALAR",
-							},
+							"name":   "String",
+							"kind":   "SCALAR",
+							"ofType": nil,
 						},
 					},
 				},
@@ -386,3 +383,394 @@ func TestMutateAndGetPayload_AddsErrors(t *testing.T) {
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func TestMutation_WithClientMutationId_BehavesCorrectly_AllowsOmittingClientMutationId(t *testing.T) {
+	query := `
+        mutation M {
+          simpleMutation(input: {}) {
+            result
+            clientMutationId
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"simpleMutation": map[string]interface{}{
+				"result":           1,
+				"clientMutationId": nil,
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        mutationTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+type mutationTestViewerKey struct{}
+
+func newIdempotentMutationTestSchema(store pagination.IdempotencyStore, middleware ...pagination.MutationMiddleware) (graphql.Schema, *int) {
+	calls := 0
+	mutation := pagination.MutationWithClientMutationID(pagination.MutationConfig{
+		Name: "CountingMutation",
+		InputFields: graphql.InputObjectConfigFieldMap{
+			"amount": &graphql.InputObjectFieldConfig{
+				Type: graphql.Int,
+			},
+		},
+		OutputFields: graphql.Fields{
+			"result": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			calls++
+			return map[string]interface{}{
+				"result": calls,
+			}, nil
+		},
+		IdempotencyStore: store,
+		Viewer: func(ctx context.Context) string {
+			viewer, _ := ctx.Value(mutationTestViewerKey{}).(string)
+			return viewer
+		},
+		Middleware: middleware,
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"countingMutation": mutation,
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return schema, &calls
+}
+
+func TestMutation_WithIdempotencyStore_ReplaysRetriedMutations(t *testing.T) {
+	schema, calls := newIdempotentMutationTestSchema(pagination.NewMemoryIdempotencyStore(time.Minute))
+	do := func(viewer string, query string) *graphql.Result {
+		return graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: query,
+			Context:       context.WithValue(context.Background(), mutationTestViewerKey{}, viewer),
+		})
+	}
+	query := `mutation M { countingMutation(input: {clientMutationId: "abc"}) { result clientMutationId } }`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"countingMutation": map[string]interface{}{
+				"result":           1,
+				"clientMutationId": "abc",
+			},
+		},
+	}
+
+	first := do("alice", query)
+	retry := do("alice", query)
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, first))
+	}
+	if !reflect.DeepEqual(retry, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, retry))
+	}
+	if *calls != 1 {
+		t.Fatalf("expected mutation to run once, ran %v times", *calls)
+	}
+
+	// other viewers and other client mutation ids are not replayed
+	do("bob", query)
+	do("alice", `mutation M { countingMutation(input: {clientMutationId: "abcd"}) { result } }`)
+	// mutations without client mutation ids are never replayed
+	do("alice", `mutation M { countingMutation(input: {}) { result } }`)
+	do("alice", `mutation M { countingMutation(input: {}) { result } }`)
+	if *calls != 5 {
+		t.Fatalf("expected mutation to run 5 times, ran %v times", *calls)
+	}
+}
+
+func TestMutation_WithIdempotencyStore_RejectsReusedClientMutationIDs(t *testing.T) {
+	schema, calls := newIdempotentMutationTestSchema(pagination.NewMemoryIdempotencyStore(time.Minute))
+	do := func(query string) *graphql.Result {
+		return graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: query,
+			Context:       context.WithValue(context.Background(), mutationTestViewerKey{}, "alice"),
+		})
+	}
+	result := do(`mutation M { countingMutation(input: {clientMutationId: "abc", amount: 1}) { result } }`)
+	assert.Empty(t, result.Errors)
+	result = do(`mutation M { countingMutation(input: {clientMutationId: "abc", amount: 2}) { result } }`)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, pagination.ErrClientMutationIDReused.Error(), result.Errors[0].Message)
+	}
+	assert.Equal(t, 1, *calls)
+}
+
+func TestMutation_WithIdempotencyStore_RunsConcurrentRetriesOnce(t *testing.T) {
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	mutation := pagination.MutationWithClientMutationID(pagination.MutationConfig{
+		Name: "SlowMutation",
+		OutputFields: graphql.Fields{
+			"result": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			close(started)
+			<-release
+			return map[string]interface{}{"result": 1}, nil
+		},
+		IdempotencyStore: pagination.NewMemoryIdempotencyStore(time.Minute),
+		Viewer:           func(ctx context.Context) string { return "alice" },
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Mutation",
+		Fields: graphql.Fields{"slowMutation": mutation},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func() *graphql.Result {
+		return graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: `mutation M { slowMutation(input: {clientMutationId: "abc"}) { result } }`,
+		})
+	}
+
+	first := make(chan *graphql.Result)
+	go func() { first <- do() }()
+	<-started
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := do()
+			if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, pagination.ErrMutationInProgress.Error(), result.Errors[0].Message)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+	assert.Empty(t, (<-first).Errors)
+
+	// completed mutations are replayed
+	assert.Empty(t, do().Errors)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestMutation_WithIdempotencyStore_RunsMiddlewareOnReplays(t *testing.T) {
+	allowed := true
+	schema, calls := newIdempotentMutationTestSchema(
+		pagination.NewMemoryIdempotencyStore(time.Minute),
+		func(next pagination.MutationFn) pagination.MutationFn {
+			return func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+				if !allowed {
+					return nil, errors.New("Forbidden")
+				}
+				return next(inputMap, info, ctx)
+			}
+		},
+	)
+	do := func() *graphql.Result {
+		return graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: `mutation M { countingMutation(input: {clientMutationId: "abc"}) { result } }`,
+			Context:       context.WithValue(context.Background(), mutationTestViewerKey{}, "alice"),
+		})
+	}
+	assert.Empty(t, do().Errors)
+	allowed = false
+	result := do()
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Forbidden", result.Errors[0].Message)
+	}
+	allowed = true
+	result = do()
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"countingMutation": map[string]interface{}{"result": 1},
+	}, result.Data)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestMutation_WithIdempotencyStore_DoesNotReplayAnonymousMutations(t *testing.T) {
+	schema, calls := newIdempotentMutationTestSchema(pagination.NewMemoryIdempotencyStore(time.Minute))
+	for i := 0; i < 2; i++ {
+		result := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: `mutation M { countingMutation(input: {clientMutationId: "0"}) { result } }`,
+			Context:       context.Background(),
+		})
+		assert.Empty(t, result.Errors)
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestMutation_WithIdempotencyStore_RequiresViewer(t *testing.T) {
+	assert.Panics(t, func() {
+		pagination.MutationWithClientMutationID(pagination.MutationConfig{
+			Name:             "CountingMutation",
+			IdempotencyStore: pagination.NewMemoryIdempotencyStore(time.Minute),
+		})
+	})
+}
+
+var userErrorsMutationTestSchema graphql.Schema
+
+func init() {
+	mutation := pagination.MutationWithClientMutationID(pagination.MutationConfig{
+		Name: "RenameUser",
+		InputFields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+		},
+		OutputFields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			switch inputMap["name"] {
+			case "":
+				return nil, pagination.NewUserError([]string{"name"}, "Name can't be blank", "BLANK")
+			case "root":
+				return nil, pagination.UserErrors{
+					pagination.NewUserError([]string{"name"}, "Name is reserved", "RESERVED"),
+					pagination.NewUserError(nil, "Try again later", ""),
+				}
+			case "crash":
+				return nil, NotFoundError
+			}
+			return map[string]interface{}{
+				"name": inputMap["name"],
+			}, nil
+		},
+		UserErrors: true,
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"renameUser": mutation,
+		},
+	})
+	userErrorsMutationTestSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+}
+
+func TestMutation_WithUserErrors_ReturnsEmptyUserErrorsOnSuccess(t *testing.T) {
+	query := `
+        mutation M {
+          renameUser(input: {name: "Dan"}) {
+            name
+            userErrors { field message code }
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"renameUser": map[string]interface{}{
+				"name":       "Dan",
+				"userErrors": []interface{}{},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        userErrorsMutationTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestMutation_WithUserErrors_FillsUserErrors(t *testing.T) {
+	query := `
+        mutation M {
+          blank: renameUser(input: {name: ""}) {
+            name
+            userErrors { field message code }
+          }
+          reserved: renameUser(input: {name: "root"}) {
+            userErrors { field message code }
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"blank": map[string]interface{}{
+				"name": nil,
+				"userErrors": []interface{}{
+					map[string]interface{}{
+						"field":   []interface{}{"name"},
+						"message": "Name can't be blank",
+						"code":    "BLANK",
+					},
+				},
+			},
+			"reserved": map[string]interface{}{
+				"userErrors": []interface{}{
+					map[string]interface{}{
+						"field":   []interface{}{"name"},
+						"message": "Name is reserved",
+						"code":    "RESERVED",
+					},
+					map[string]interface{}{
+						"field":   []interface{}{},
+						"message": "Try again later",
+						"code":    "",
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        userErrorsMutationTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestMutation_WithUserErrors_KeepsUnexpectedErrorsTopLevel(t *testing.T) {
+	query := `
+        mutation M {
+          renameUser(input: {name: "crash"}) {
+            userErrors { message }
+          }
+        }
+      `
+	result := graphql.Do(graphql.Params{
+		Schema:        userErrorsMutationTestSchema,
+		RequestString: query,
+	})
+	expectedData := map[string]interface{}{
+		"renameUser": nil,
+	}
+	if !reflect.DeepEqual(result.Data, expectedData) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expectedData, result.Data))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != NotFoundError.Error() {
+		t.Fatalf("expected top-level %v error, got %v", NotFoundError, result.Errors)
+	}
+}
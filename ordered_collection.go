@@ -0,0 +1,265 @@
+package pagination
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+const (
+	orderedPrefix = "ordered:"
+	maxSkipLevel  = 32
+)
+
+// KeyToCursor creates the cursor string from the sort key of an item
+func KeyToCursor[K cmp.Ordered](key K) ListCursor {
+	b, _ := json.Marshal(key)
+	return ListCursor(base64.StdEncoding.EncodeToString(append([]byte(orderedPrefix), b...)))
+}
+
+// CursorToKey re-derives the sort key of an item from the cursor string
+func CursorToKey[K cmp.Ordered](cursor ListCursor) (K, error) {
+	var key K
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), orderedPrefix) {
+		return key, errors.New("Invalid cursor")
+	}
+	if err := json.Unmarshal(b[len(orderedPrefix):], &key); err != nil {
+		return key, errors.New("Invalid cursor")
+	}
+	return key, nil
+}
+
+type skipNode[K cmp.Ordered] struct {
+	key  K
+	item interface{}
+	next []*skipNode[K]
+	prev *skipNode[K]
+}
+
+// OrderedCollection is an in-memory collection of items sorted by key, backed
+// by a skiplist. It is a ListSource whose cursors are item keys, so that pages
+// are found in O(log n) without copying the items, and remain valid when items
+// are added or removed. It is safe for concurrent use.
+type OrderedCollection[K cmp.Ordered] struct {
+	mu     sync.RWMutex
+	keyFn  func(item interface{}) K
+	head   *skipNode[K]
+	tail   *skipNode[K]
+	level  int
+	length int
+}
+
+// NewOrderedCollection is an OrderedCollection constructor. keyFn returns the
+// unique sort key of an item.
+func NewOrderedCollection[K cmp.Ordered](keyFn func(item interface{}) K) *OrderedCollection[K] {
+	return &OrderedCollection[K]{
+		keyFn: keyFn,
+		head:  &skipNode[K]{next: make([]*skipNode[K], maxSkipLevel)},
+		level: 1,
+	}
+}
+
+// Len returns the number of items
+func (c *OrderedCollection[K]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.length
+}
+
+// seek fills update with the last node of each level whose key is less than
+// key, and returns the first node whose key is not
+func (c *OrderedCollection[K]) seek(key K, update []*skipNode[K]) *skipNode[K] {
+	node := c.head
+	for level := c.level - 1; level >= 0; level-- {
+		for node.next[level] != nil && node.next[level].key < key {
+			node = node.next[level]
+		}
+		if update != nil {
+			update[level] = node
+		}
+	}
+	return node.next[0]
+}
+
+// Put adds item to the collection, or replaces the item with the same key
+func (c *OrderedCollection[K]) Put(item interface{}) {
+	key := c.keyFn(item)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	update := make([]*skipNode[K], maxSkipLevel)
+	if node := c.seek(key, update); node != nil && node.key == key {
+		node.item = item
+		return
+	}
+
+	level := 1
+	for level < maxSkipLevel && rand.Intn(4) == 0 {
+		level++
+	}
+	for ; c.level < level; c.level++ {
+		update[c.level] = c.head
+	}
+	node := &skipNode[K]{key: key, item: item, next: make([]*skipNode[K], level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+	if update[0] != c.head {
+		node.prev = update[0]
+	}
+	if node.next[0] != nil {
+		node.next[0].prev = node
+	} else {
+		c.tail = node
+	}
+	c.length++
+}
+
+// Get returns the item with the given key
+func (c *OrderedCollection[K]) Get(key K) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if node := c.seek(key, nil); node != nil && node.key == key {
+		return node.item, true
+	}
+	return nil, false
+}
+
+// Delete removes the item with the given key, and reports whether it was found
+func (c *OrderedCollection[K]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	update := make([]*skipNode[K], maxSkipLevel)
+	node := c.seek(key, update)
+	if node == nil || node.key != key {
+		return false
+	}
+	for i := range node.next {
+		update[i].next[i] = node.next[i]
+	}
+	if node.next[0] != nil {
+		node.next[0].prev = node.prev
+	} else {
+		c.tail = node.prev
+	}
+	for c.level > 1 && c.head.next[c.level-1] == nil {
+		c.level--
+	}
+	c.length--
+	return true
+}
+
+// List implements ListSource
+func (c *OrderedCollection[K]) List(args ListArguments, ctx context.Context) (*List, error) {
+	var after, before K
+	if args.After != "" {
+		var err error
+		if after, err = CursorToKey[K](args.After); err != nil {
+			return nil, err
+		}
+	}
+	if args.Before != "" {
+		var err error
+		if before, err = CursorToKey[K](args.Before); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// first node of the range between the cursors
+	first := c.head.next[0]
+	if args.After != "" {
+		first = c.seek(after, nil)
+		if first != nil && first.key == after {
+			first = first.next[0]
+		}
+	}
+	inRange := func(node *skipNode[K]) bool {
+		return node != nil && (args.Before == "" || node.key < before)
+	}
+
+	// walks are interrupted when ctx is done, returning the items found so far
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	var nodes []*skipNode[K]
+	hasNextPage, hasPreviousPage, interrupted := false, false, false
+	switch {
+	case args.First != -1 || args.Last == -1:
+		node := first
+		for ; inRange(node) && (args.First == -1 || len(nodes) < args.First); node = node.next[0] {
+			if interrupted = isDone(done); interrupted {
+				break
+			}
+			nodes = append(nodes, node)
+		}
+		hasNextPage = (args.First != -1 || interrupted) && inRange(node)
+		if args.Last != -1 && len(nodes) > args.Last {
+			nodes = nodes[len(nodes)-args.Last:]
+			hasPreviousPage = true
+		}
+	default:
+		node := c.tail
+		if args.Before != "" {
+			if node = c.seek(before, nil); node == nil {
+				node = c.tail
+			} else {
+				node = node.prev
+			}
+		}
+		for ; node != nil && (args.After == "" || after < node.key) && len(nodes) < args.Last; node = node.prev {
+			if interrupted = isDone(done); interrupted {
+				break
+			}
+			nodes = append(nodes, node)
+		}
+		hasPreviousPage = node != nil && (args.After == "" || after < node.key)
+		for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+			nodes[i], nodes[j] = nodes[j], nodes[i]
+		}
+	}
+
+	list := NewList()
+	list.TotalCount = c.length
+	list.PageInfo.HasPreviousPage = hasPreviousPage
+	list.PageInfo.HasNextPage = hasNextPage
+	for _, node := range nodes {
+		list.Items = append(list.Items, node.item)
+	}
+	if len(nodes) > 0 {
+		list.PageInfo.StartCursor = KeyToCursor(nodes[0].key)
+		list.PageInfo.EndCursor = KeyToCursor(nodes[len(nodes)-1].key)
+	}
+	if interrupted {
+		return list, ContextError(ctx)
+	}
+	return list, nil
+}
+
+// CursorFor implements CursorLocator
+func (c *OrderedCollection[K]) CursorFor(object interface{}, ctx context.Context) (ListCursor, error) {
+	key := c.keyFn(object)
+	if _, ok := c.Get(key); !ok {
+		return "", nil
+	}
+	return KeyToCursor(key), nil
+}
+
+// OrderedItemCursor returns the ItemCursorFn of lists returned by an
+// OrderedCollection, given the sort key function of its items
+func OrderedItemCursor[K cmp.Ordered](keyFn func(item interface{}) K) ItemCursorFn {
+	return func(list *List, index int) ListCursor {
+		return KeyToCursor(keyFn(list.Items[index]))
+	}
+}
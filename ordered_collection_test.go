@@ -0,0 +1,160 @@
+package pagination_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func letterKey(item interface{}) string {
+	return item.(string)
+}
+
+func newLetterCollection(letters ...string) *pagination.OrderedCollection[string] {
+	c := pagination.NewOrderedCollection(letterKey)
+	for _, letter := range letters {
+		c.Put(letter)
+	}
+	return c
+}
+
+func TestKeyToCursor_RoundTrips(t *testing.T) {
+	key, err := pagination.CursorToKey[int](pagination.KeyToCursor(42))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, key)
+
+	_, err = pagination.CursorToKey[string](pagination.OffsetToCursor(42))
+	assert.EqualError(t, err, "Invalid cursor")
+}
+
+func TestOrderedCollection_PaginatesLikeListFromArray(t *testing.T) {
+	c := newLetterCollection("E", "C", "A", "D", "B")
+	// offset cursors of ListFromArray map to key cursors of the collection
+	keyCursor := func(cursor pagination.ListCursor) pagination.ListCursor {
+		if cursor == "" {
+			return ""
+		}
+		return pagination.KeyToCursor(arrayListTestLetters[pagination.GetOffsetWithDefault(cursor, 0)].(string))
+	}
+
+	limits := []interface{}{nil, 0, 2, 10}
+	cursors := []pagination.ListCursor{"", pagination.OffsetToCursor(0), pagination.OffsetToCursor(1), pagination.OffsetToCursor(3), pagination.OffsetToCursor(4)}
+	for _, first := range limits {
+		for _, last := range limits {
+			for _, after := range cursors {
+				for _, before := range cursors {
+					filters := map[string]interface{}{}
+					if first != nil {
+						filters["first"] = first
+					}
+					if last != nil {
+						filters["last"] = last
+					}
+					args := pagination.NewListArguments(filters)
+					args.After, args.Before = after, before
+					expected := pagination.ListFromArray(arrayListTestLetters, args)
+
+					args.After, args.Before = keyCursor(after), keyCursor(before)
+					list, err := c.List(args, context.Background())
+					assert.NoError(t, err)
+
+					name := fmt.Sprintf("first: %v, last: %v, after: %q, before: %q", first, last, after, before)
+					assert.Equal(t, expected.Items, list.Items, name)
+					assert.Equal(t, expected.PageInfo.HasNextPage, list.PageInfo.HasNextPage, name)
+					assert.Equal(t, expected.PageInfo.HasPreviousPage, list.PageInfo.HasPreviousPage, name)
+					assert.Equal(t, keyCursor(expected.PageInfo.StartCursor), list.PageInfo.StartCursor, name)
+					assert.Equal(t, keyCursor(expected.PageInfo.EndCursor), list.PageInfo.EndCursor, name)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderedCollection_CursorsSurviveMutations(t *testing.T) {
+	c := newLetterCollection("A", "B", "C", "D", "E")
+	first, err := c.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"A", "B"}, first.Items)
+
+	c.Put("AA")
+	assert.True(t, c.Delete("C"))
+	assert.False(t, c.Delete("C"))
+
+	next, err := c.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(first.PageInfo.EndCursor),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"D", "E"}, next.Items)
+	assert.False(t, next.PageInfo.HasNextPage)
+	assert.Equal(t, 5, next.TotalCount)
+
+	// the cursor of a deleted item is still a valid position
+	prev, err := c.List(pagination.NewListArguments(map[string]interface{}{
+		"last":   2,
+		"before": string(pagination.KeyToCursor("C")),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"AA", "B"}, prev.Items)
+	assert.True(t, prev.PageInfo.HasPreviousPage)
+
+	cursor, err := c.CursorFor("D", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.KeyToCursor("D"), cursor)
+	cursor, err = c.CursorFor("C", context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.ListCursor(""), cursor)
+}
+
+func TestOrderedCollection_ReplacesItemsWithSameKey(t *testing.T) {
+	c := pagination.NewOrderedCollection(func(item interface{}) int {
+		return item.(*user).ID
+	})
+	c.Put(&user{ID: 1, Name: "Dan"})
+	c.Put(&user{ID: 1, Name: "Daniel"})
+	assert.Equal(t, 1, c.Len())
+	item, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Daniel", item.(*user).Name)
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+}
+
+func TestOrderedCollection_IsSafeForConcurrentUse(t *testing.T) {
+	c := pagination.NewOrderedCollection(func(item interface{}) int {
+		return item.(int)
+	})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < 1000; i += 4 {
+				c.Put(i)
+				if i%3 == 0 {
+					c.Delete(i)
+				}
+				_, err := c.List(pagination.NewListArguments(map[string]interface{}{
+					"first": 10,
+					"after": string(pagination.KeyToCursor(i / 2)),
+				}), context.Background())
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 666, c.Len())
+	list, err := c.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 3,
+		"after": string(pagination.KeyToCursor(500)),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{502, 503, 505}, list.Items)
+}
@@ -0,0 +1,258 @@
+package pagination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// QueryExecutor executes the GraphQL queries of a `PageIterator`. Errors are
+// failures to execute the query, which are retried, while GraphQL errors are
+// reported in the result.
+type QueryExecutor interface {
+	Execute(query string, variables map[string]interface{}, ctx context.Context) (*graphql.Result, error)
+}
+
+// QueryExecutorFn is an adapter to use ordinary functions as QueryExecutor
+type QueryExecutorFn func(query string, variables map[string]interface{}, ctx context.Context) (*graphql.Result, error)
+
+// Execute implements QueryExecutor
+func (fn QueryExecutorFn) Execute(query string, variables map[string]interface{}, ctx context.Context) (*graphql.Result, error) {
+	return fn(query, variables, ctx)
+}
+
+// SchemaExecutor returns a QueryExecutor executing queries against a local schema
+func SchemaExecutor(schema graphql.Schema) QueryExecutor {
+	return QueryExecutorFn(func(query string, variables map[string]interface{}, ctx context.Context) (*graphql.Result, error) {
+		return graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  query,
+			VariableValues: variables,
+			Context:        ctx,
+		}), nil
+	})
+}
+
+// HTTPStatusError is returned by `HTTPExecutor` for responses which are not successful
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+// Error implements error
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("Unexpected status %v", e.StatusCode)
+}
+
+// HTTPExecutor returns a QueryExecutor posting queries to a GraphQL endpoint,
+// with http.DefaultClient if client is nil
+func HTTPExecutor(endpoint string, client *http.Client) QueryExecutor {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return QueryExecutorFn(func(query string, variables map[string]interface{}, ctx context.Context) (*graphql.Result, error) {
+		body, err := json.Marshal(map[string]interface{}{
+			"query":     query,
+			"variables": variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, &HTTPStatusError{StatusCode: res.StatusCode}
+		}
+		result := &graphql.Result{}
+		if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
+
+// ResultError is returned when a page query returns GraphQL errors
+type ResultError struct {
+	Errors []gqlerrors.FormattedError
+}
+
+// Error implements error
+func (e *ResultError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// PageIteratorConfig is the configuration of a `PageIterator`
+type PageIteratorConfig struct {
+	Executor QueryExecutor `json:"-"`
+	// Query must declare `$first: Int` and `$after: String` variables, given
+	// to the list field
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+	// Path is the path of the list in the data of results, e.g. ["faction", "ships"]
+	Path     []string `json:"path"`
+	PageSize int      `json:"pageSize"`
+	// Retries is the number of times failed queries are retried, waiting
+	// RetryDelay (doubled after each retry) in between
+	Retries    int           `json:"retries"`
+	RetryDelay time.Duration `json:"retryDelay"`
+}
+
+// PageIterator iterates the pages of a list of a GraphQL API, following the
+// end cursor of each page. A page is loaded only once the previous one has
+// been consumed, so that slow consumers don't buffer the list.
+// Items are decoded from JSON, e.g. objects are map[string]interface{}.
+type PageIterator struct {
+	config PageIteratorConfig
+}
+
+// NewPageIterator is a PageIterator constructor. The page size is 10 and the
+// retry delay 100ms by default.
+func NewPageIterator(config PageIteratorConfig) *PageIterator {
+	if config.PageSize == 0 {
+		config.PageSize = 10
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 100 * time.Millisecond
+	}
+	return &PageIterator{config: config}
+}
+
+// Pages returns an iterator over the pages of the list. It stops after the
+// first error, which is yielded with a nil page.
+func (it *PageIterator) Pages(ctx context.Context) iter.Seq2[*List, error] {
+	return func(yield func(*List, error) bool) {
+		var after ListCursor
+		for {
+			list, err := it.page(after, ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(list, nil) {
+				return
+			}
+			if !list.PageInfo.HasNextPage {
+				return
+			}
+			if list.PageInfo.EndCursor == "" || list.PageInfo.EndCursor == after {
+				yield(nil, errors.New("End cursor did not advance"))
+				return
+			}
+			after = list.PageInfo.EndCursor
+		}
+	}
+}
+
+// Items returns an iterator over the items of the list. It stops after the
+// first error, which is yielded with a nil item.
+func (it *PageIterator) Items(ctx context.Context) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		for list, err := range it.Pages(ctx) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, item := range list.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// page loads the page after the cursor, retrying failed queries
+func (it *PageIterator) page(after ListCursor, ctx context.Context) (*List, error) {
+	variables := make(map[string]interface{}, len(it.config.Variables)+2)
+	for name, value := range it.config.Variables {
+		variables[name] = value
+	}
+	variables["first"] = it.config.PageSize
+	if after != "" {
+		variables["after"] = string(after)
+	}
+
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	delay := it.config.RetryDelay
+	for retry := 0; ; retry++ {
+		result, err := it.config.Executor.Execute(it.config.Query, variables, ctx)
+		if err == nil {
+			return it.decode(result)
+		}
+		if retry >= it.config.Retries || !isRetryable(err) {
+			return nil, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-done:
+			return nil, ContextError(ctx)
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable tells whether a failed query may succeed later
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// decode returns the list at the path of the data of result
+func (it *PageIterator) decode(result *graphql.Result) (*List, error) {
+	if len(result.Errors) > 0 {
+		return nil, &ResultError{Errors: result.Errors}
+	}
+	data := result.Data
+	for _, name := range it.config.Path {
+		fields, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("No list at path %v", strings.Join(it.config.Path, "."))
+		}
+		data = fields[name]
+	}
+	if data == nil {
+		return nil, fmt.Errorf("No list at path %v", strings.Join(it.config.Path, "."))
+	}
+	// results of local schemas are decoded like results of HTTP endpoints
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	list := NewList()
+	if err := json.Unmarshal(b, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
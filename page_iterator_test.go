@@ -0,0 +1,166 @@
+package pagination_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const pageIteratorTestQuery = `query Friends($first: Int, $after: String) {
+	user {
+		friends(first: $first, after: $after) {
+			items { name }
+			pageInfo { endCursor hasNextPage }
+		}
+	}
+}`
+
+// newFriendsServer serves listTestSchema, failing the first requests with the given status
+func newFriendsServer(requests *int32, failures int32, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		result := graphql.Do(graphql.Params{
+			Schema:         listTestSchema,
+			RequestString:  body.Query,
+			VariableValues: body.Variables,
+			Context:        r.Context(),
+		})
+		json.NewEncoder(w).Encode(result)
+	}))
+}
+
+func friendNames(t *testing.T, it *pagination.PageIterator) []interface{} {
+	var names []interface{}
+	for item, err := range it.Items(context.Background()) {
+		if !assert.NoError(t, err) {
+			break
+		}
+		names = append(names, item.(map[string]interface{})["name"])
+	}
+	return names
+}
+
+func TestPageIterator_FollowsEndCursors(t *testing.T) {
+	var requests int32
+	server := newFriendsServer(&requests, 0, 0)
+	defer server.Close()
+
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor: pagination.HTTPExecutor(server.URL, nil),
+		Query:    pageIteratorTestQuery,
+		Path:     []string{"user", "friends"},
+		PageSize: 2,
+	})
+	assert.Equal(t, []interface{}{"Dan", "Nick", "Lee", "Joe", "Tim"}, friendNames(t, it))
+	assert.Equal(t, int32(3), requests)
+}
+
+func TestPageIterator_LoadsPagesOnDemand(t *testing.T) {
+	var requests int32
+	server := newFriendsServer(&requests, 0, 0)
+	defer server.Close()
+
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor: pagination.HTTPExecutor(server.URL, nil),
+		Query:    pageIteratorTestQuery,
+		Path:     []string{"user", "friends"},
+		PageSize: 2,
+	})
+	for list, err := range it.Pages(context.Background()) {
+		assert.NoError(t, err)
+		assert.Len(t, list.Items, 2)
+		assert.True(t, list.PageInfo.HasNextPage)
+		break
+	}
+	assert.Equal(t, int32(1), requests)
+}
+
+func TestPageIterator_RetriesFailedQueries(t *testing.T) {
+	var requests int32
+	server := newFriendsServer(&requests, 2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor:   pagination.HTTPExecutor(server.URL, nil),
+		Query:      pageIteratorTestQuery,
+		Path:       []string{"user", "friends"},
+		PageSize:   5,
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+	})
+	assert.Equal(t, []interface{}{"Dan", "Nick", "Lee", "Joe", "Tim"}, friendNames(t, it))
+	assert.Equal(t, int32(3), requests)
+}
+
+func TestPageIterator_DoesNotRetryClientErrors(t *testing.T) {
+	var requests int32
+	server := newFriendsServer(&requests, 1, http.StatusBadRequest)
+	defer server.Close()
+
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor:   pagination.HTTPExecutor(server.URL, nil),
+		Query:      pageIteratorTestQuery,
+		Path:       []string{"user", "friends"},
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+	})
+	for item, err := range it.Items(context.Background()) {
+		assert.Nil(t, item)
+		assert.Equal(t, &pagination.HTTPStatusError{StatusCode: http.StatusBadRequest}, err)
+	}
+	assert.Equal(t, int32(1), requests)
+}
+
+func TestPageIterator_ReturnsResultErrors(t *testing.T) {
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor: pagination.SchemaExecutor(listTestSchema),
+		Query:    `query Friends($first: Int, $after: String) { user { enemies(first: $first, after: $after) { items { name } } } }`,
+		Path:     []string{"user", "enemies"},
+	})
+	var errs []error
+	for _, err := range it.Items(context.Background()) {
+		errs = append(errs, err)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &pagination.ResultError{}, errs[0])
+		assert.EqualError(t, errs[0], `Cannot query field "enemies" on type "User".`)
+	}
+}
+
+func TestPageIterator_WithSchemaExecutor(t *testing.T) {
+	it := pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor: pagination.SchemaExecutor(listTestSchema),
+		Query:    pageIteratorTestQuery,
+		Path:     []string{"user", "friends"},
+		PageSize: 3,
+	})
+	assert.Equal(t, []interface{}{"Dan", "Nick", "Lee", "Joe", "Tim"}, friendNames(t, it))
+
+	it = pagination.NewPageIterator(pagination.PageIteratorConfig{
+		Executor: pagination.SchemaExecutor(listTestSchema),
+		Query:    pageIteratorTestQuery,
+		Path:     []string{"user", "foes"},
+	})
+	for _, err := range it.Items(context.Background()) {
+		assert.EqualError(t, err, "No list at path user.foes")
+	}
+}
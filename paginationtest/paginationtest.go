@@ -0,0 +1,205 @@
+// Package paginationtest implements a conformance test suite for list sources,
+// checking that they paginate like `pagination.ListFromArray`.
+package paginationtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// Config describes the list source under test
+type Config struct {
+	// NewSource returns a source listing items in the given order. Items are
+	// distinct strings sorted in ascending order, so that sorted sources can
+	// be tested.
+	NewSource func(items []interface{}) pagination.ListSource
+	// RejectsInvalidCursors tells whether the source returns an error for
+	// cursors it can't decode, rather than ignoring them like `ListFromArray`
+	RejectsInvalidCursors bool
+	// Runs is the number of randomized checks, 100 by default
+	Runs int
+	// Seed seeds the randomized checks, the current time by default
+	Seed int64
+}
+
+// Letters are the items of the behavioural matrix
+var Letters = []interface{}{"A", "B", "C", "D", "E"}
+
+// TestListSource runs the behavioural matrix of `ListFromArray` (first, last,
+// before and after combinations, invalid, consecutive and crossing cursors)
+// and randomized property checks against the source.
+// Cursors are obtained by walking the source one item at a time, so any
+// cursor format is supported.
+func TestListSource(t *testing.T, config Config) {
+	t.Run("Matrix", func(t *testing.T) {
+		TestMatrix(t, config)
+	})
+	t.Run("Random", func(t *testing.T) {
+		TestRandom(t, config)
+	})
+}
+
+// TestMatrix checks every combination of first, last, after and before
+// arguments on `Letters`
+func TestMatrix(t *testing.T, config Config) {
+	source := config.NewSource(Letters)
+	cursors, ok := walk(t, source, Letters)
+	if !ok {
+		return
+	}
+
+	limits := []int{-1, 0, 1, 2, 5, 10}
+	offsets := []int{-1, 0, 1, 2, 3, 4}
+	for _, first := range limits {
+		for _, last := range limits {
+			for _, after := range offsets {
+				for _, before := range offsets {
+					check(t, source, Letters, cursors, first, last, after, before)
+				}
+			}
+		}
+	}
+
+	args := pagination.NewListArguments(nil)
+	args.After, args.Before = "invalid", "invalid"
+	list, err := source.List(args, context.Background())
+	if config.RejectsInvalidCursors {
+		assert.Error(t, err, "invalid cursors")
+		return
+	}
+	if assert.NoError(t, err, "invalid cursors") {
+		assert.Equal(t, Letters, list.Items, "invalid cursors")
+	}
+}
+
+// TestRandom checks random arguments on random lists
+func TestRandom(t *testing.T, config Config) {
+	runs := config.Runs
+	if runs == 0 {
+		runs = 100
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	for run := 0; run < runs; run++ {
+		items := randomItems(r)
+		source := config.NewSource(items)
+		cursors, ok := walk(t, source, items)
+		if !ok {
+			t.Logf("seed: %v", seed)
+			return
+		}
+		for i := 0; i < 10; i++ {
+			n := len(items)
+			first, last := randomLimit(r, n), randomLimit(r, n)
+			after, before := r.Intn(n+1)-1, r.Intn(n+1)-1
+			if !check(t, source, items, cursors, first, last, after, before) {
+				t.Logf("seed: %v", seed)
+				return
+			}
+		}
+	}
+}
+
+// randomItems returns up to 20 distinct sorted strings
+func randomItems(r *rand.Rand) []interface{} {
+	var items []interface{}
+	for i := 0; i < 100 && len(items) < 20; i++ {
+		if r.Intn(3) == 0 {
+			items = append(items, fmt.Sprintf("%03d", i))
+		}
+	}
+	return items
+}
+
+// randomLimit returns -1 (no limit) or a limit up to n+2
+func randomLimit(r *rand.Rand, n int) int {
+	if r.Intn(3) == 0 {
+		return -1
+	}
+	return r.Intn(n + 3)
+}
+
+// walk returns the cursor of each item of source, paginating forwards one
+// item at a time
+func walk(t *testing.T, source pagination.ListSource, items []interface{}) ([]pagination.ListCursor, bool) {
+	cursors := make([]pagination.ListCursor, 0, len(items))
+	args := pagination.NewListArguments(nil)
+	args.First = 1
+	for {
+		list, err := source.List(args, context.Background())
+		if !assert.NoError(t, err, "walk after %q", args.After) {
+			return nil, false
+		}
+		if len(list.Items) == 0 {
+			break
+		}
+		if !assert.Len(t, list.Items, 1, "walk after %q", args.After) ||
+			!assert.Equal(t, list.PageInfo.StartCursor, list.PageInfo.EndCursor, "walk after %q", args.After) {
+			return nil, false
+		}
+		cursors = append(cursors, list.PageInfo.EndCursor)
+		if len(cursors) > len(items) {
+			break
+		}
+		args.After = list.PageInfo.EndCursor
+	}
+	if !assert.Len(t, cursors, len(items), "walk") {
+		return nil, false
+	}
+	return cursors, true
+}
+
+// check compares a page of source with the page of `ListFromArray`, offsets
+// of -1 meaning no cursor
+func check(t *testing.T, source pagination.ListSource, items []interface{}, cursors []pagination.ListCursor, first, last, after, before int) bool {
+	args := pagination.NewListArguments(nil)
+	args.First, args.Last = first, last
+	sourceArgs := args
+	if after != -1 {
+		args.After, sourceArgs.After = pagination.OffsetToCursor(after), cursors[after]
+	}
+	if before != -1 {
+		args.Before, sourceArgs.Before = pagination.OffsetToCursor(before), cursors[before]
+	}
+	expected := pagination.ListFromArray(items, args)
+	sourceCursor := func(cursor pagination.ListCursor) pagination.ListCursor {
+		if cursor == "" {
+			return ""
+		}
+		return cursors[pagination.GetOffsetWithDefault(cursor, 0)]
+	}
+
+	name := fmt.Sprintf("items: %v, first: %v, last: %v, after: %v, before: %v", len(items), first, last, after, before)
+	list, err := source.List(sourceArgs, context.Background())
+	if !assert.NoError(t, err, name) {
+		return false
+	}
+	ok := assert.Equal(t, expected.Items, nonNil(list.Items), name)
+	ok = assert.Equal(t, expected.PageInfo.HasPreviousPage, list.PageInfo.HasPreviousPage, "hasPreviousPage, "+name) && ok
+	ok = assert.Equal(t, expected.PageInfo.HasNextPage, list.PageInfo.HasNextPage, "hasNextPage, "+name) && ok
+	ok = assert.Equal(t, sourceCursor(expected.PageInfo.StartCursor), list.PageInfo.StartCursor, "startCursor, "+name) && ok
+	ok = assert.Equal(t, sourceCursor(expected.PageInfo.EndCursor), list.PageInfo.EndCursor, "endCursor, "+name) && ok
+	// empty windows may not count the items, like `ListFromArray` for crossing cursors
+	if len(expected.Items) > 0 {
+		ok = assert.Equal(t, len(items), list.TotalCount, "totalCount, "+name) && ok
+	}
+	return ok
+}
+
+// nonNil returns an empty slice instead of nil, like `ListFromArray`
+func nonNil(items []interface{}) []interface{} {
+	if items == nil {
+		return []interface{}{}
+	}
+	return items
+}
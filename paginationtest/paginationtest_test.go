@@ -0,0 +1,74 @@
+package paginationtest_test
+
+import (
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stratumn/graphql-pagination-go/paginationtest"
+)
+
+func stringKey(item interface{}) string {
+	return item.(string)
+}
+
+func TestArrayListSource(t *testing.T) {
+	paginationtest.TestListSource(t, paginationtest.Config{
+		NewSource: func(items []interface{}) pagination.ListSource {
+			return pagination.ArrayListSource(items)
+		},
+	})
+}
+
+func TestListIndex(t *testing.T) {
+	paginationtest.TestListSource(t, paginationtest.Config{
+		NewSource: func(items []interface{}) pagination.ListSource {
+			return pagination.NewListIndex(items, nil)
+		},
+	})
+}
+
+func TestSnapshotList(t *testing.T) {
+	paginationtest.TestListSource(t, paginationtest.Config{
+		NewSource: func(items []interface{}) pagination.ListSource {
+			return pagination.NewSnapshotList(items, 0)
+		},
+		RejectsInvalidCursors: true,
+	})
+}
+
+func TestOrderedCollection(t *testing.T) {
+	paginationtest.TestListSource(t, paginationtest.Config{
+		NewSource: func(items []interface{}) pagination.ListSource {
+			c := pagination.NewOrderedCollection(stringKey)
+			for _, item := range items {
+				c.Put(item)
+			}
+			return c
+		},
+		RejectsInvalidCursors: true,
+	})
+}
+
+func TestMergedListSource(t *testing.T) {
+	paginationtest.TestListSource(t, paginationtest.Config{
+		NewSource: func(items []interface{}) pagination.ListSource {
+			// deal the items between an array and an ordered collection
+			var array []interface{}
+			c := pagination.NewOrderedCollection(stringKey)
+			for i, item := range items {
+				if i%3 == 0 {
+					array = append(array, item)
+				} else {
+					c.Put(item)
+				}
+			}
+			return pagination.MergeListSources(func(a, b interface{}) bool {
+				return a.(string) < b.(string)
+			},
+				pagination.MergeSource{Source: pagination.ArrayListSource(array)},
+				pagination.MergeSource{Source: c, ItemCursor: pagination.OrderedItemCursor(stringKey)},
+			)
+		},
+		RejectsInvalidCursors: true,
+	})
+}
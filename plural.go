@@ -1,19 +1,50 @@
 package pagination
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
 	"github.com/graphql-go/graphql"
 )
 
 // ResolveSingleInputFn is ...
 type ResolveSingleInputFn func(input interface{}) interface{}
 
+// ResolveInputFn resolves a single input of a plural identifying root field.
+// Its error is reported on the matching element of the list only.
+type ResolveInputFn func(input interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error)
+
+// ResolveManyInputsFn resolves all the inputs of a plural identifying root field at once.
+// It returns either a map from input to result, or a slice of results aligned
+// with inputs. Inputs missing from the map resolve to null.
+type ResolveManyInputsFn func(inputs []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error)
+
 // PluralIdentifyingRootFieldConfig is ...
+//
+// ResolveManyInputs takes precedence over ResolveInput, which takes precedence
+// over ResolveSingleInput. Inputs are resolved at most Concurrency at a time
+// (one at a time by default), repeated inputs are only resolved once, and
+// results keep the order of inputs.
+// When List is given, the field returns its list type and accepts `ListArgs`:
+// inputs are paginated first with the list policy, and only the inputs of the
+// requested window are resolved.
+// Inputs not resolved within Timeout (the timeout of the list policy by
+// default) resolve to null with an `ErrTimeout` error.
 type PluralIdentifyingRootFieldConfig struct {
-	ArgName            string               `json:"argName"`
-	InputType          graphql.Input        `json:"inputType"`
-	OutputType         graphql.Output       `json:"outputType"`
-	ResolveSingleInput ResolveSingleInputFn `json:"resolveSingleInput"`
-	Description        string               `json:"description"`
+	ArgName            string                  `json:"argName"`
+	InputType          graphql.Input           `json:"inputType"`
+	OutputType         graphql.Output          `json:"outputType"`
+	ResolveSingleInput ResolveSingleInputFn    `json:"resolveSingleInput"`
+	ResolveInput       ResolveInputFn          `json:"-"`
+	ResolveManyInputs  ResolveManyInputsFn     `json:"-"`
+	Concurrency        int                     `json:"concurrency"`
+	Timeout            time.Duration           `json:"timeout"`
+	List               *GraphQLListDefinitions `json:"list"`
+	Description        string                  `json:"description"`
 }
 
 // PluralIdentifyingRootField is ...
@@ -24,10 +55,44 @@ func PluralIdentifyingRootField(config PluralIdentifyingRootFieldConfig) *graphq
 			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(config.InputType))),
 		}
 	}
+	var outputType graphql.Output = graphql.NewList(config.OutputType)
+	if config.List != nil {
+		inputArgs = NewListArgs(inputArgs)
+		outputType = config.List.ListType
+	}
+
+	resolveInput := config.ResolveInput
+	if resolveInput == nil && config.ResolveSingleInput != nil {
+		resolveInput = func(input interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+			return config.ResolveSingleInput(input), nil
+		}
+	}
+	timeout := config.Timeout
+	if timeout == 0 && config.List != nil {
+		timeout = config.List.Policy.Timeout
+	}
+	resolveInputs := func(inputs []interface{}, p graphql.ResolveParams) ([]interface{}, error) {
+		if timeout > 0 {
+			ctx := p.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			var cancel context.CancelFunc
+			p.Context, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if config.ResolveManyInputs != nil {
+			return resolveManyPluralInputs(inputs, config.ResolveManyInputs, p.Info, p.Context)
+		}
+		if resolveInput == nil {
+			return nil, nil
+		}
+		return resolvePluralInputs(inputs, resolveInput, config.Concurrency, p.Info, p.Context), nil
+	}
 
 	return &graphql.Field{
 		Description: config.Description,
-		Type:        graphql.NewList(config.OutputType),
+		Type:        outputType,
 		Args:        inputArgs,
 		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
 			inputs, ok := p.Args[config.ArgName]
@@ -35,15 +100,24 @@ func PluralIdentifyingRootField(config PluralIdentifyingRootFieldConfig) *graphq
 				return nil, nil
 			}
 
-			if config.ResolveSingleInput == nil {
-				return nil, nil
-			}
 			switch inputs := inputs.(type) {
 			case []interface{}:
-				res := []interface{}{}
-				for _, input := range inputs {
-					r := config.ResolveSingleInput(input)
-					res = append(res, r)
+				if config.List != nil {
+					args, err := config.List.ListArguments(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					list := ListFromArray(inputs, args)
+					items, err := resolveInputs(list.Items, p)
+					if err != nil || items == nil {
+						return nil, err
+					}
+					list.Items = items
+					return list, nil
+				}
+				res, err := resolveInputs(inputs, p)
+				if err != nil || res == nil {
+					return nil, err
 				}
 				return res, nil
 			}
@@ -51,3 +125,215 @@ func PluralIdentifyingRootField(config PluralIdentifyingRootFieldConfig) *graphq
 		},
 	}
 }
+
+// distinctPluralInputs returns the distinct inputs, and for each input the
+// index of its distinct value.
+func distinctPluralInputs(inputs []interface{}) ([]interface{}, []int) {
+	distinct := []interface{}{}
+	positions := make([]int, len(inputs))
+	byKey := map[interface{}]int{}
+	for i, input := range inputs {
+		key := valueKey(input)
+		position, ok := byKey[key]
+		if !ok {
+			position = len(distinct)
+			byKey[key] = position
+			distinct = append(distinct, input)
+		}
+		positions[i] = position
+	}
+	return distinct, positions
+}
+
+// resolvePluralInputs resolves the distinct inputs with bounded concurrency.
+// Failed elements are returned as thunks so that GraphQL reports their error
+// with the path of the element in the list. When ctx is done, the elements
+// resolved so far are returned, the others fail with `ContextError(ctx)`.
+func resolvePluralInputs(inputs []interface{}, resolveInput ResolveInputFn, concurrency int, info graphql.ResolveInfo, ctx context.Context) []interface{} {
+	distinct, positions := distinctPluralInputs(inputs)
+	results := make([]interface{}, len(distinct))
+	errs := make([]error, len(distinct))
+	resolved := make([]bool, len(distinct))
+
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+	// late results are dropped once the resolution is interrupted or ctx is done
+	var mu sync.Mutex
+	interrupted := false
+	var wg sync.WaitGroup
+start:
+	for i, input := range distinct {
+		select {
+		case semaphore <- struct{}{}:
+		case <-done:
+			break start
+		}
+		wg.Add(1)
+		go func(i int, input interface{}) {
+			var result interface{}
+			var err error
+			defer func() {
+				if rec := recover(); rec != nil {
+					err = fmt.Errorf("%v", rec)
+				}
+				mu.Lock()
+				if !interrupted && !isDone(done) {
+					results[i], errs[i], resolved[i] = result, err, true
+				}
+				mu.Unlock()
+				<-semaphore
+				wg.Done()
+			}()
+			result, err = resolveInput(input, info, ctx)
+		}(i, input)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-done:
+	}
+	mu.Lock()
+	interrupted = true
+	mu.Unlock()
+
+	res := make([]interface{}, len(inputs))
+	for i, position := range positions {
+		err := errs[position]
+		if !resolved[position] {
+			err = ContextError(ctx)
+		}
+		if err != nil {
+			res[i] = func() (interface{}, error) {
+				return nil, err
+			}
+			continue
+		}
+		res[i] = results[position]
+	}
+	return res
+}
+
+// resolveManyPluralInputs resolves the distinct inputs in a single batch, and
+// maps the batch results back to the inputs. It fails when ctx is done before
+// the batch is resolved.
+func resolveManyPluralInputs(inputs []interface{}, resolveManyInputs ResolveManyInputsFn, info graphql.ResolveInfo, ctx context.Context) ([]interface{}, error) {
+	distinct, positions := distinctPluralInputs(inputs)
+	var batch interface{}
+	var err error
+	resolved := make(chan struct{})
+	go func() {
+		defer close(resolved)
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("%v", rec)
+			}
+		}()
+		batch, err = resolveManyInputs(distinct, info, ctx)
+	}()
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case <-resolved:
+	case <-done:
+		return nil, ContextError(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, len(distinct))
+	batchVal := reflect.ValueOf(batch)
+	switch batchVal.Kind() {
+	case reflect.Invalid:
+	case reflect.Slice, reflect.Array:
+		if batchVal.Len() != len(distinct) {
+			return nil, fmt.Errorf("ResolveManyInputs returned %v results for %v inputs", batchVal.Len(), len(distinct))
+		}
+		for i := range results {
+			results[i] = batchVal.Index(i).Interface()
+		}
+	case reflect.Map:
+		keyType := batchVal.Type().Key()
+		for i, input := range distinct {
+			key, ok := pluralMapKey(reflect.ValueOf(input), keyType)
+			if !ok {
+				continue
+			}
+			if result := batchVal.MapIndex(key); result.IsValid() {
+				results[i] = result.Interface()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("ResolveManyInputs must return a map or a slice, got %T", batch)
+	}
+
+	res := make([]interface{}, len(inputs))
+	for i, position := range positions {
+		res[i] = results[position]
+	}
+	return res, nil
+}
+
+// pluralMapKey returns input as a key of type keyType, if it can be one.
+// Inputs are only converted between kinds of the same family, e.g. int to
+// int64 but not int to string, which Go converts to a rune, and conversions
+// have to preserve the value.
+func pluralMapKey(input reflect.Value, keyType reflect.Type) (reflect.Value, bool) {
+	if !input.IsValid() || !input.Type().Comparable() {
+		return reflect.Value{}, false
+	}
+	if input.Type().AssignableTo(keyType) {
+		return input, true
+	}
+	family := pluralKindFamily(input.Kind())
+	if family == "" || family != pluralKindFamily(keyType.Kind()) {
+		return reflect.Value{}, false
+	}
+	key := input.Convert(keyType)
+	if key.Convert(input.Type()).Interface() != input.Interface() {
+		return reflect.Value{}, false
+	}
+	return key, true
+}
+
+// pluralKindFamily returns the family of kinds whose values pluralMapKey
+// converts between, if any
+func pluralKindFamily(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return ""
+}
+
+type valueJSONKey string
+
+// valueKey returns a key identifying equal values, values which can't be
+// map keys (e.g. input objects) being compared by their JSON encoding.
+func valueKey(value interface{}) interface{} {
+	if value == nil || reflect.TypeOf(value).Comparable() {
+		return value
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return valueJSONKey(fmt.Sprintf("%#v", value))
+	}
+	return valueJSONKey(b)
+}
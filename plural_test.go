@@ -1,9 +1,13 @@
 package pagination_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
@@ -235,3 +239,320 @@ func TestPluralIdentifyingRootField_Configuration_ArgNames_WrongArgNameSpecified
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func TestPluralIdentifyingRootField_ResolveInput_ReportsErrorsPerElement(t *testing.T) {
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"usernames": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:     "usernames",
+					Description: "Map from a username to the user",
+					InputType:   graphql.String,
+					OutputType:  pluralTestUserType,
+					ResolveInput: func(username interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						if username == "unknown" {
+							return nil, errors.New("Unknown user")
+						}
+						return map[string]interface{}{
+							"username": username,
+						}, nil
+					},
+				}),
+			},
+		}),
+	})
+	query := `{
+      usernames(usernames:["dschafer", "unknown", "schrockn"]) {
+        username
+      }
+    }`
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	assert.EqualValues(t, map[string]interface{}{
+		"usernames": []interface{}{
+			map[string]interface{}{"username": "dschafer"},
+			nil,
+			map[string]interface{}{"username": "schrockn"},
+		},
+	}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Unknown user", result.Errors[0].Message)
+		assert.Equal(t, []interface{}{"usernames", 1}, result.Errors[0].Path)
+	}
+}
+
+func TestPluralIdentifyingRootField_ResolveInput_ResolvesConcurrentlyInOrder(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	calls := map[string]int{}
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"usernames": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:     "usernames",
+					InputType:   graphql.String,
+					OutputType:  pluralTestUserType,
+					Concurrency: 2,
+					ResolveInput: func(username interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						mu.Lock()
+						running++
+						if running > maxRunning {
+							maxRunning = running
+						}
+						calls[username.(string)]++
+						mu.Unlock()
+
+						time.Sleep(10 * time.Millisecond)
+
+						mu.Lock()
+						running--
+						mu.Unlock()
+						return map[string]interface{}{
+							"username": username,
+						}, nil
+					},
+				}),
+			},
+		}),
+	})
+	query := `{
+      usernames(usernames:["a", "b", "c", "a", "d", "b"]) {
+        username
+      }
+    }`
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"usernames": []interface{}{
+			map[string]interface{}{"username": "a"},
+			map[string]interface{}{"username": "b"},
+			map[string]interface{}{"username": "c"},
+			map[string]interface{}{"username": "a"},
+			map[string]interface{}{"username": "d"},
+			map[string]interface{}{"username": "b"},
+		},
+	}, result.Data)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}, calls)
+	assert.Equal(t, 2, maxRunning)
+}
+
+func TestPluralIdentifyingRootField_ResolveManyInputs_MapsResultsToInputs(t *testing.T) {
+	batches := [][]interface{}{}
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"fromMap": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "usernames",
+					InputType:  graphql.String,
+					OutputType: pluralTestUserType,
+					ResolveManyInputs: func(usernames []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						batches = append(batches, usernames)
+						users := map[string]map[string]interface{}{}
+						for _, username := range usernames {
+							if username != "unknown" {
+								users[username.(string)] = map[string]interface{}{"username": username}
+							}
+						}
+						return users, nil
+					},
+				}),
+				"fromSlice": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "usernames",
+					InputType:  graphql.String,
+					OutputType: pluralTestUserType,
+					ResolveManyInputs: func(usernames []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						users := make([]interface{}, len(usernames))
+						for i, username := range usernames {
+							users[i] = map[string]interface{}{"url": fmt.Sprintf("www.facebook.com/%v", username)}
+						}
+						return users, nil
+					},
+				}),
+			},
+		}),
+	})
+	query := `{
+      fromMap(usernames:["dschafer", "unknown", "leebyron", "dschafer"]) {
+        username
+      }
+      fromSlice(usernames:["dschafer", "leebyron", "dschafer"]) {
+        url
+      }
+    }`
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"fromMap": []interface{}{
+			map[string]interface{}{"username": "dschafer"},
+			nil,
+			map[string]interface{}{"username": "leebyron"},
+			map[string]interface{}{"username": "dschafer"},
+		},
+		"fromSlice": []interface{}{
+			map[string]interface{}{"url": "www.facebook.com/dschafer"},
+			map[string]interface{}{"url": "www.facebook.com/leebyron"},
+			map[string]interface{}{"url": "www.facebook.com/dschafer"},
+		},
+	}, result.Data)
+	assert.Equal(t, [][]interface{}{{"dschafer", "unknown", "leebyron"}}, batches)
+}
+
+func TestPluralIdentifyingRootField_ResolveManyInputs_ConvertsKeysOfSameKind(t *testing.T) {
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"byInt64": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "ids",
+					InputType:  graphql.Int,
+					OutputType: graphql.String,
+					ResolveManyInputs: func(ids []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						return map[int64]string{2: "two"}, nil
+					},
+				}),
+				// ints are not runes
+				"byString": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "ids",
+					InputType:  graphql.Int,
+					OutputType: graphql.String,
+					ResolveManyInputs: func(ids []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						return map[string]string{"A": "letter A"}, nil
+					},
+				}),
+			},
+		}),
+	})
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ byInt64(ids: [2, -1]) byString(ids: [65]) }`,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"byInt64":  []interface{}{"two", nil},
+		"byString": []interface{}{nil},
+	}, result.Data)
+}
+
+func TestPluralIdentifyingRootField_ResolveManyInputs_RejectsMisalignedSlices(t *testing.T) {
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"usernames": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "usernames",
+					InputType:  graphql.String,
+					OutputType: pluralTestUserType,
+					ResolveManyInputs: func(usernames []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						return []interface{}{}, nil
+					},
+				}),
+			},
+		}),
+	})
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ usernames(usernames:["dschafer"]) { username } }`,
+	})
+	assert.EqualValues(t, map[string]interface{}{"usernames": nil}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "ResolveManyInputs returned 0 results for 1 inputs", result.Errors[0].Message)
+	}
+}
+
+func TestPluralIdentifyingRootField_ResolveManyInputs_RecoversPanics(t *testing.T) {
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"usernames": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "usernames",
+					InputType:  graphql.String,
+					OutputType: pluralTestUserType,
+					ResolveManyInputs: func(usernames []interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						panic("database crashed")
+					},
+				}),
+			},
+		}),
+	})
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ usernames(usernames:["dschafer"]) { username } }`,
+	})
+	assert.EqualValues(t, map[string]interface{}{"usernames": nil}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "database crashed", result.Errors[0].Message)
+	}
+}
+
+func TestPluralIdentifyingRootField_List_PaginatesInputs(t *testing.T) {
+	resolved := []interface{}{}
+	userListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "User",
+		ItemType: pluralTestUserType,
+	})
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"usernames": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:    "usernames",
+					InputType:  graphql.String,
+					OutputType: pluralTestUserType,
+					List:       userListDef,
+					ResolveInput: func(username interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						resolved = append(resolved, username)
+						return map[string]interface{}{
+							"username": username,
+						}, nil
+					},
+				}),
+			},
+		}),
+	})
+	query := `{
+      usernames(usernames:["a", "b", "c", "d", "e"], first: 2, after: "YXJyYXljb25uZWN0aW9uOjA=") {
+        items {
+          username
+        }
+        pageInfo {
+          startCursor
+          endCursor
+          hasNextPage
+        }
+        totalCount
+      }
+    }`
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"usernames": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"username": "b"},
+				map[string]interface{}{"username": "c"},
+			},
+			"pageInfo": map[string]interface{}{
+				"startCursor": "YXJyYXljb25uZWN0aW9uOjE=",
+				"endCursor":   "YXJyYXljb25uZWN0aW9uOjI=",
+				"hasNextPage": true,
+			},
+			"totalCount": 5,
+		},
+	}, result.Data)
+	// only the inputs of the window are resolved
+	assert.Equal(t, []interface{}{"b", "c"}, resolved)
+}
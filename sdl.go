@@ -0,0 +1,545 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// PaginatedDirective declares the `@paginated` directive, which SDL given to
+// `SchemaFromSDL` may include
+const PaginatedDirective = `directive @paginated(defaultPageSize: Int, maxPageSize: Int) on FIELD_DEFINITION`
+
+// ListSourceResolveFn returns the list source of a `@paginated` field
+type ListSourceResolveFn func(p graphql.ResolveParams) (ListSource, error)
+
+// SDLConfig is the configuration of `SchemaFromSDL`. Maps are keyed by type
+// name, or by "Type.field" for fields.
+type SDLConfig struct {
+	SDL string `json:"sdl"`
+	// Resolvers resolve fields, the default resolver being used otherwise
+	Resolvers map[string]graphql.FieldResolveFn `json:"-"`
+	// ListSources return the list sources of `@paginated` fields
+	ListSources map[string]ListSourceResolveFn `json:"-"`
+	// Lists configure the lists of the item types of `@paginated` fields, their
+	// name and item type being given by the SDL
+	Lists map[string]ListConfig `json:"-"`
+	// ResolveTypes resolve the concrete types of interfaces and unions
+	ResolveTypes map[string]graphql.ResolveTypeFn `json:"-"`
+	IsTypeOf     map[string]graphql.IsTypeOfFn    `json:"-"`
+	// Scalars implement the custom scalars of the SDL
+	Scalars map[string]*graphql.Scalar `json:"-"`
+}
+
+// sdlBuilder builds the types of a SDL document
+type sdlBuilder struct {
+	config SDLConfig
+	types  map[string]graphql.Type
+	lists  map[string]*GraphQLListDefinitions
+	// fields are filled once all named types exist, before the schema resolves
+	// the thunks of types
+	objectFields      map[string]graphql.Fields
+	objectInterfaces  map[string][]*graphql.Interface
+	inputObjectFields map[string]graphql.InputObjectConfigFieldMap
+	bound             map[string]bool
+}
+
+// SchemaFromSDL builds a schema from type definitions, in which list fields
+// with the `@paginated` directive, e.g. `ships: [Ship!]! @paginated`, are
+// list fields of `ListDefinitions`: their type is the `ShipList` type, they
+// accept `ListArgs`, and they load the list source of `ListSources` (see
+// `Load`). The directive arguments override the list policy.
+// The root types are given by the schema definition, or are the Query,
+// Mutation and Subscription types.
+func SchemaFromSDL(config SDLConfig) (graphql.Schema, error) {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(config.SDL),
+		Name: "GraphQL SDL",
+	})})
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	b := &sdlBuilder{
+		config: config,
+		types: map[string]graphql.Type{
+			"String":  graphql.String,
+			"Int":     graphql.Int,
+			"Float":   graphql.Float,
+			"Boolean": graphql.Boolean,
+			"ID":      graphql.ID,
+		},
+		lists:             map[string]*GraphQLListDefinitions{},
+		objectFields:      map[string]graphql.Fields{},
+		objectInterfaces:  map[string][]*graphql.Interface{},
+		inputObjectFields: map[string]graphql.InputObjectConfigFieldMap{},
+		bound:             map[string]bool{},
+	}
+	return b.build(document)
+}
+
+func (b *sdlBuilder) build(document *ast.Document) (graphql.Schema, error) {
+	var schemaDef *ast.SchemaDefinition
+	var unions []*ast.UnionDefinition
+	var named []string
+	for _, def := range document.Definitions {
+		var name string
+		switch def := def.(type) {
+		case *ast.SchemaDefinition:
+			schemaDef = def
+			continue
+		case *ast.DirectiveDefinition:
+			continue
+		case *ast.ScalarDefinition:
+			name = def.Name.Value
+			if _, ok := b.types[name]; !ok {
+				scalar, ok := b.config.Scalars[name]
+				if !ok {
+					return graphql.Schema{}, fmt.Errorf("No implementation of scalar %v", name)
+				}
+				b.types[name] = scalar
+			}
+			continue
+		case *ast.EnumDefinition:
+			name = def.Name.Value
+			values := graphql.EnumValueConfigMap{}
+			for _, value := range def.Values {
+				values[value.Name.Value] = &graphql.EnumValueConfig{
+					Value:       value.Name.Value,
+					Description: description(value.Description),
+				}
+			}
+			if err := b.define(name, graphql.NewEnum(graphql.EnumConfig{
+				Name:        name,
+				Values:      values,
+				Description: description(def.Description),
+			})); err != nil {
+				return graphql.Schema{}, err
+			}
+		case *ast.InputObjectDefinition:
+			name = def.Name.Value
+			fields := graphql.InputObjectConfigFieldMap{}
+			b.inputObjectFields[name] = fields
+			if err := b.define(name, graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: name,
+				Fields: graphql.InputObjectConfigFieldMapThunk(func() graphql.InputObjectConfigFieldMap {
+					return fields
+				}),
+				Description: description(def.Description),
+			})); err != nil {
+				return graphql.Schema{}, err
+			}
+		case *ast.InterfaceDefinition:
+			name = def.Name.Value
+			fields := graphql.Fields{}
+			b.objectFields[name] = fields
+			if err := b.define(name, graphql.NewInterface(graphql.InterfaceConfig{
+				Name: name,
+				Fields: graphql.FieldsThunk(func() graphql.Fields {
+					return fields
+				}),
+				ResolveType: b.config.ResolveTypes[name],
+				Description: description(def.Description),
+			})); err != nil {
+				return graphql.Schema{}, err
+			}
+		case *ast.ObjectDefinition:
+			name = def.Name.Value
+			fields := graphql.Fields{}
+			b.objectFields[name] = fields
+			if err := b.define(name, graphql.NewObject(graphql.ObjectConfig{
+				Name: name,
+				Fields: graphql.FieldsThunk(func() graphql.Fields {
+					return fields
+				}),
+				Interfaces: graphql.InterfacesThunk(func() []*graphql.Interface {
+					return b.objectInterfaces[name]
+				}),
+				IsTypeOf:    b.config.IsTypeOf[name],
+				Description: description(def.Description),
+			})); err != nil {
+				return graphql.Schema{}, err
+			}
+		case *ast.UnionDefinition:
+			unions = append(unions, def)
+			name = def.Name.Value
+		default:
+			return graphql.Schema{}, fmt.Errorf("Unsupported definition %v", def.GetKind())
+		}
+		named = append(named, name)
+	}
+
+	// unions need the objects
+	for _, def := range unions {
+		name := def.Name.Value
+		objects := make([]*graphql.Object, len(def.Types))
+		for i, t := range def.Types {
+			object, ok := b.types[t.Name.Value].(*graphql.Object)
+			if !ok {
+				return graphql.Schema{}, fmt.Errorf("Member %v of union %v is not an object type", t.Name.Value, name)
+			}
+			objects[i] = object
+		}
+		if err := b.define(name, graphql.NewUnion(graphql.UnionConfig{
+			Name:        name,
+			Types:       objects,
+			ResolveType: b.config.ResolveTypes[name],
+			Description: description(def.Description),
+		})); err != nil {
+			return graphql.Schema{}, err
+		}
+	}
+
+	for _, def := range document.Definitions {
+		var err error
+		switch def := def.(type) {
+		case *ast.InputObjectDefinition:
+			err = b.inputFields(def)
+		case *ast.InterfaceDefinition:
+			err = b.fields(def.Name.Value, def.Fields, false)
+		case *ast.ObjectDefinition:
+			err = b.fields(def.Name.Value, def.Fields, true)
+			for _, t := range def.Interfaces {
+				iface, ok := b.types[t.Name.Value].(*graphql.Interface)
+				if !ok {
+					err = fmt.Errorf("%v implemented by %v is not an interface", t.Name.Value, def.Name.Value)
+					break
+				}
+				b.objectInterfaces[def.Name.Value] = append(b.objectInterfaces[def.Name.Value], iface)
+			}
+		}
+		if err != nil {
+			return graphql.Schema{}, err
+		}
+	}
+	if err := b.checkBindings(); err != nil {
+		return graphql.Schema{}, err
+	}
+
+	roots := map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"}
+	if schemaDef != nil {
+		roots = map[string]string{}
+		for _, operationType := range schemaDef.OperationTypes {
+			roots[operationType.Operation] = operationType.Type.Name.Value
+		}
+	}
+	schemaConfig := graphql.SchemaConfig{}
+	for operation, target := range map[string]**graphql.Object{
+		"query":        &schemaConfig.Query,
+		"mutation":     &schemaConfig.Mutation,
+		"subscription": &schemaConfig.Subscription,
+	} {
+		name, ok := roots[operation]
+		if !ok {
+			continue
+		}
+		object, ok := b.types[name].(*graphql.Object)
+		if !ok {
+			if schemaDef != nil || operation == "query" {
+				return graphql.Schema{}, fmt.Errorf("Schema has no %v type %v", operation, name)
+			}
+			continue
+		}
+		*target = object
+	}
+	// types only reachable through interfaces
+	for _, name := range named {
+		schemaConfig.Types = append(schemaConfig.Types, b.types[name])
+	}
+	return graphql.NewSchema(schemaConfig)
+}
+
+// define adds a named type
+func (b *sdlBuilder) define(name string, t graphql.Type) error {
+	if _, ok := b.types[name]; ok {
+		return fmt.Errorf("Type %v is defined twice", name)
+	}
+	b.types[name] = t
+	return nil
+}
+
+// typeOf returns the type of a type reference
+func (b *sdlBuilder) typeOf(t ast.Type) (graphql.Type, error) {
+	switch t := t.(type) {
+	case *ast.Named:
+		named, ok := b.types[t.Name.Value]
+		if !ok {
+			return nil, fmt.Errorf("Unknown type %v", t.Name.Value)
+		}
+		return named, nil
+	case *ast.List:
+		item, err := b.typeOf(t.Type)
+		if err != nil {
+			return nil, err
+		}
+		return graphql.NewList(item), nil
+	case *ast.NonNull:
+		item, err := b.typeOf(t.Type)
+		if err != nil {
+			return nil, err
+		}
+		return graphql.NewNonNull(item), nil
+	}
+	return nil, fmt.Errorf("Unsupported type %v", t.GetKind())
+}
+
+// inputTypeOf returns the type of a type reference of an argument or input field
+func (b *sdlBuilder) inputTypeOf(t ast.Type, owner string) (graphql.Input, error) {
+	ttype, err := b.typeOf(t)
+	if err != nil {
+		return nil, err
+	}
+	input, ok := ttype.(graphql.Input)
+	if !ok || !isInputType(ttype) {
+		return nil, fmt.Errorf("Type %v of %v is not an input type", ttype, owner)
+	}
+	return input, nil
+}
+
+// isInputType tells whether a type may be the type of an argument
+func isInputType(ttype graphql.Type) bool {
+	switch ttype := ttype.(type) {
+	case *graphql.List:
+		return isInputType(ttype.OfType)
+	case *graphql.NonNull:
+		return isInputType(ttype.OfType)
+	case *graphql.Scalar, *graphql.Enum, *graphql.InputObject:
+		return true
+	}
+	return false
+}
+
+// inputFields fills the fields of an input object
+func (b *sdlBuilder) inputFields(def *ast.InputObjectDefinition) error {
+	fields := b.inputObjectFields[def.Name.Value]
+	for _, field := range def.Fields {
+		key := def.Name.Value + "." + field.Name.Value
+		ttype, err := b.inputTypeOf(field.Type, key)
+		if err != nil {
+			return err
+		}
+		fields[field.Name.Value] = &graphql.InputObjectFieldConfig{
+			Type:         ttype,
+			DefaultValue: valueOf(field.DefaultValue),
+			Description:  description(field.Description),
+		}
+	}
+	return nil
+}
+
+// fields fills the fields of an object or an interface, binding resolvers to
+// the fields of objects
+func (b *sdlBuilder) fields(typeName string, defs []*ast.FieldDefinition, bind bool) error {
+	fields := b.objectFields[typeName]
+	for _, def := range defs {
+		key := typeName + "." + def.Name.Value
+		ttype, err := b.typeOf(def.Type)
+		if err != nil {
+			return err
+		}
+		output, ok := ttype.(graphql.Output)
+		if !ok || isInputObject(ttype) {
+			return fmt.Errorf("Type %v of %v is not an output type", ttype, key)
+		}
+		field := &graphql.Field{
+			Name:        def.Name.Value,
+			Type:        output,
+			Args:        graphql.FieldConfigArgument{},
+			Description: description(def.Description),
+		}
+		for _, arg := range def.Arguments {
+			argType, err := b.inputTypeOf(arg.Type, key+"("+arg.Name.Value+")")
+			if err != nil {
+				return err
+			}
+			field.Args[arg.Name.Value] = &graphql.ArgumentConfig{
+				Type:         argType,
+				DefaultValue: valueOf(arg.DefaultValue),
+				Description:  description(arg.Description),
+			}
+		}
+		if deprecated := directive(def.Directives, "deprecated"); deprecated != nil {
+			field.DeprecationReason = "No longer supported"
+			if reason, ok := directiveArgs(deprecated)["reason"].(string); ok {
+				field.DeprecationReason = reason
+			}
+		}
+		if bind {
+			field.Resolve = b.config.Resolvers[key]
+			if field.Resolve != nil {
+				b.bound[key] = true
+			}
+		}
+		if paginated := directive(def.Directives, "paginated"); paginated != nil {
+			if err := b.paginate(key, field, def.Type, paginated, bind); err != nil {
+				return err
+			}
+		}
+		fields[def.Name.Value] = field
+	}
+	return nil
+}
+
+// paginate makes a list field a `@paginated` field
+func (b *sdlBuilder) paginate(key string, field *graphql.Field, t ast.Type, paginated *ast.Directive, bind bool) error {
+	nonNull := false
+	if n, ok := t.(*ast.NonNull); ok {
+		nonNull, t = true, n.Type
+	}
+	list, ok := t.(*ast.List)
+	if !ok {
+		return fmt.Errorf("Paginated field %v is not a list", key)
+	}
+	t = list.Type
+	if n, ok := t.(*ast.NonNull); ok {
+		t = n.Type
+	}
+	named, ok := t.(*ast.Named)
+	if !ok {
+		return fmt.Errorf("Paginated field %v is not a list of named types", key)
+	}
+	itemName := named.Name.Value
+	itemType, ok := b.types[itemName].(graphql.Output)
+	if !ok || isInputObject(itemType) {
+		return fmt.Errorf("Type %v of %v is not an output type", itemName, key)
+	}
+
+	defs, ok := b.lists[itemName]
+	if !ok {
+		if _, ok := b.types[itemName+"List"]; ok {
+			return fmt.Errorf("Type %v conflicts with the list of %v", itemName+"List", itemName)
+		}
+		config := b.config.Lists[itemName]
+		config.Name, config.ItemType = itemName, itemType
+		defs = ListDefinitions(config)
+		b.lists[itemName] = defs
+	}
+	field.Type = defs.ListType
+	if nonNull {
+		field.Type = graphql.NewNonNull(defs.ListType)
+	}
+	for name, arg := range ListArgs {
+		if _, ok := field.Args[name]; ok {
+			return fmt.Errorf("Paginated field %v already has argument %v", key, name)
+		}
+		field.Args[name] = arg
+	}
+
+	// the directive overrides the list policy
+	fieldDefs := *defs
+	args := directiveArgs(paginated)
+	if size, ok := args["defaultPageSize"].(int); ok {
+		fieldDefs.Policy.DefaultPageSize = size
+	}
+	if size, ok := args["maxPageSize"].(int); ok {
+		fieldDefs.Policy.MaxPageSize = size
+	}
+	if !bind {
+		return nil
+	}
+	listSource, ok := b.config.ListSources[key]
+	if !ok {
+		if field.Resolve == nil {
+			return fmt.Errorf("No list source for paginated field %v", key)
+		}
+		return nil
+	}
+	b.bound[key] = true
+	field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+		source, err := listSource(p)
+		if err != nil {
+			return nil, err
+		}
+		return fieldDefs.Load(source, p)
+	}
+	return nil
+}
+
+// checkBindings reports resolvers and list sources of unknown fields
+func (b *sdlBuilder) checkBindings() error {
+	for key := range b.config.Resolvers {
+		if !b.bound[key] {
+			return fmt.Errorf("Resolver of unknown field %v", key)
+		}
+	}
+	for key := range b.config.ListSources {
+		if !b.bound[key] {
+			return fmt.Errorf("List source of unknown paginated field %v", key)
+		}
+	}
+	return nil
+}
+
+// isInputObject tells whether a type is an input object, possibly wrapped
+func isInputObject(ttype graphql.Type) bool {
+	switch ttype := ttype.(type) {
+	case *graphql.List:
+		return isInputObject(ttype.OfType)
+	case *graphql.NonNull:
+		return isInputObject(ttype.OfType)
+	case *graphql.InputObject:
+		return true
+	}
+	return false
+}
+
+// directive returns the directive with the given name, if any
+func directive(directives []*ast.Directive, name string) *ast.Directive {
+	for _, d := range directives {
+		if d.Name.Value == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// directiveArgs returns the values of the arguments of a directive
+func directiveArgs(d *ast.Directive) map[string]interface{} {
+	args := map[string]interface{}{}
+	for _, arg := range d.Arguments {
+		args[arg.Name.Value] = valueOf(arg.Value)
+	}
+	return args
+}
+
+// valueOf returns the Go value of a constant
+func valueOf(value ast.Value) interface{} {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		i, _ := strconv.Atoi(value.Value)
+		return i
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(value.Value, 64)
+		return f
+	case *ast.StringValue:
+		return value.Value
+	case *ast.BooleanValue:
+		return value.Value
+	case *ast.EnumValue:
+		return value.Value
+	case *ast.ListValue:
+		values := make([]interface{}, len(value.Values))
+		for i, v := range value.Values {
+			values[i] = valueOf(v)
+		}
+		return values
+	case *ast.ObjectValue:
+		fields := make(map[string]interface{}, len(value.Fields))
+		for _, field := range value.Fields {
+			fields[field.Name.Value] = valueOf(field.Value)
+		}
+		return fields
+	}
+	return nil
+}
+
+// description returns the text of a description, if any
+func description(value *ast.StringValue) string {
+	if value == nil {
+		return ""
+	}
+	return value.Value
+}
@@ -0,0 +1,183 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type sdlShip struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sdlFaction struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Ships []interface{} `json:"-"`
+}
+
+var sdlTestRebels = &sdlFaction{
+	ID:   "1",
+	Name: "Alliance to Restore the Republic",
+	Ships: []interface{}{
+		&sdlShip{ID: "1", Name: "X-Wing"},
+		&sdlShip{ID: "2", Name: "Y-Wing"},
+		&sdlShip{ID: "3", Name: "A-Wing"},
+	},
+}
+
+const sdlTestSDL = pagination.PaginatedDirective + `
+
+interface Node {
+	id: ID!
+}
+
+"A ship of a faction."
+type Ship implements Node {
+	id: ID!
+	name: String
+}
+
+type Faction implements Node {
+	id: ID!
+	name: String
+	ships: [Ship!]! @paginated(maxPageSize: 2)
+	allShips: [Ship] @paginated
+}
+
+enum Side {
+	LIGHT
+	DARK
+}
+
+type Query {
+	faction(side: Side = LIGHT): Faction
+	node(id: ID!): Node
+}
+`
+
+func sdlTestConfig() pagination.SDLConfig {
+	return pagination.SDLConfig{
+		SDL: sdlTestSDL,
+		Resolvers: map[string]graphql.FieldResolveFn{
+			"Query.faction": func(p graphql.ResolveParams) (interface{}, error) {
+				if p.Args["side"] != "LIGHT" {
+					return nil, nil
+				}
+				return sdlTestRebels, nil
+			},
+		},
+		ListSources: map[string]pagination.ListSourceResolveFn{
+			"Faction.ships": func(p graphql.ResolveParams) (pagination.ListSource, error) {
+				return pagination.ArrayListSource(p.Source.(*sdlFaction).Ships), nil
+			},
+			"Faction.allShips": func(p graphql.ResolveParams) (pagination.ListSource, error) {
+				return pagination.ArrayListSource(p.Source.(*sdlFaction).Ships), nil
+			},
+		},
+		Lists: map[string]pagination.ListConfig{
+			"Ship": {Policy: pagination.ListPolicy{DefaultPageSize: 1}},
+		},
+		ResolveTypes: map[string]graphql.ResolveTypeFn{
+			"Node": func(p graphql.ResolveTypeParams) *graphql.Object {
+				return nil
+			},
+		},
+	}
+}
+
+func TestSchemaFromSDL_WiresPaginatedFields(t *testing.T) {
+	schema, err := pagination.SchemaFromSDL(sdlTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `{
+			faction {
+				name
+				ships(first: 2) {
+					items { name }
+					pageInfo { hasNextPage }
+					totalCount
+				}
+				allShips {
+					items { id }
+				}
+			}
+		}`,
+	})
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{
+		"faction": map[string]interface{}{
+			"name": "Alliance to Restore the Republic",
+			"ships": map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "X-Wing"},
+					map[string]interface{}{"name": "Y-Wing"},
+				},
+				"pageInfo":   map[string]interface{}{"hasNextPage": true},
+				"totalCount": 3,
+			},
+			// default page size of the Ship list
+			"allShips": map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "1"},
+				},
+			},
+		},
+	}, result.Data)
+
+	result = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ faction { ships(first: 3) { totalCount } } }`,
+	})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Page size 3 exceeds maximum 2", result.Errors[0].Message)
+	}
+}
+
+func TestSchemaFromSDL_ExposesSDLTypes(t *testing.T) {
+	schema, err := pagination.SchemaFromSDL(sdlTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ship := schema.Type("Ship").(*graphql.Object)
+	assert.Equal(t, "A ship of a faction.", ship.Description())
+	assert.Equal(t, "Node", ship.Interfaces()[0].Name())
+
+	ships := schema.Type("Faction").(*graphql.Object).Fields()["ships"]
+	assert.Equal(t, "ShipList!", ships.Type.String())
+	var args []string
+	for _, arg := range ships.Args {
+		args = append(args, arg.Name())
+	}
+	assert.ElementsMatch(t, []string{"first", "last", "before", "after"}, args)
+	allShips := schema.Type("Faction").(*graphql.Object).Fields()["allShips"]
+	assert.Equal(t, "ShipList", allShips.Type.String())
+}
+
+func TestSchemaFromSDL_ReportsErrors(t *testing.T) {
+	for sdl, expected := range map[string]string{
+		`type Query { ships: [Ship] @paginated }`:                                                               "Unknown type Ship",
+		`type Ship { id: ID } type Query { ship: Ship @paginated }`:                                             "Paginated field Query.ship is not a list",
+		`type Ship { id: ID } type Query { ships: [Ship] @paginated }`:                                          "No list source for paginated field Query.ships",
+		`type Query { id: ID } scalar Date`:                                                                     "No implementation of scalar Date",
+		`type Query { id: ID } type Query { name: String }`:                                                     "Type Query is defined twice",
+		`type Ship { id: ID } type ShipList { id: ID } type Query { ships: [Ship] @paginated(maxPageSize: 1) }`: "Type ShipList conflicts with the list of Ship",
+	} {
+		config := sdlTestConfig()
+		config.SDL = sdl
+		config.Resolvers, config.ListSources = nil, nil
+		_, err := pagination.SchemaFromSDL(config)
+		assert.EqualError(t, err, expected, sdl)
+	}
+
+	config := sdlTestConfig()
+	config.Resolvers["Query.fraction"] = config.Resolvers["Query.faction"]
+	_, err := pagination.SchemaFromSDL(config)
+	assert.EqualError(t, err, "Resolver of unknown field Query.fraction")
+}
@@ -0,0 +1,242 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const snapshotPrefix = "snapshot:"
+
+// ListError is a pagination error carrying a machine-readable code, reported
+// in the `extensions` of GraphQL errors
+type ListError struct {
+	Code    string
+	Message string
+}
+
+// Error implements error
+func (e *ListError) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e *ListError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": e.Code,
+	}
+}
+
+// ErrStaleCursor is returned when a cursor refers to a snapshot of a list which
+// is no longer retained, so that the client has to start paginating again
+var ErrStaleCursor = &ListError{Code: "STALE_CURSOR", Message: "Stale cursor"}
+
+// SnapshotToCursor creates the cursor string from a snapshot version and an offset
+func SnapshotToCursor(version int64, offset int) ListCursor {
+	str := fmt.Sprintf("%v%v:%v", snapshotPrefix, version, offset)
+	return ListCursor(base64.StdEncoding.EncodeToString([]byte(str)))
+}
+
+// CursorToSnapshot re-derives the snapshot version and the offset from the cursor string
+func CursorToSnapshot(cursor ListCursor) (int64, int, error) {
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), snapshotPrefix) {
+		return 0, 0, errors.New("Invalid cursor")
+	}
+	var version int64
+	var offset int
+	str := strings.TrimPrefix(string(b), snapshotPrefix)
+	if _, err := fmt.Sscanf(str, "%d:%d", &version, &offset); err != nil {
+		return 0, 0, errors.New("Invalid cursor")
+	}
+	return version, offset, nil
+}
+
+// SnapshotList is an in-memory collection which can be paginated while it is
+// mutated. Every mutation creates a new version of the collection, and cursors
+// record the version of the first page: following pages are served from the
+// same snapshot as long as it is retained, `ErrStaleCursor` is returned otherwise.
+// Mutations copy the items, so it is meant for small collections.
+// It is safe for concurrent use.
+type SnapshotList struct {
+	mu        sync.RWMutex
+	version   int64
+	items     []interface{}
+	retention int
+	// snapshots holds the retained previous versions
+	snapshots map[int64][]interface{}
+	versions  []int64
+}
+
+// NewSnapshotList is a SnapshotList constructor. Retention is the number of
+// previous versions kept to serve cursors of older snapshots.
+func NewSnapshotList(items []interface{}, retention int) *SnapshotList {
+	return &SnapshotList{
+		version:   1,
+		items:     append([]interface{}{}, items...),
+		retention: retention,
+		snapshots: map[int64][]interface{}{},
+	}
+}
+
+// Version returns the current version of the collection
+func (l *SnapshotList) Version() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.version
+}
+
+// Items returns a copy of the current items
+func (l *SnapshotList) Items() []interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]interface{}{}, l.items...)
+}
+
+// Len returns the current number of items
+func (l *SnapshotList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.items)
+}
+
+// Insert inserts item at index, and returns the new version
+func (l *SnapshotList) Insert(index int, item interface{}) (int64, error) {
+	return l.mutate(func(items []interface{}) ([]interface{}, error) {
+		if index < 0 || index > len(items) {
+			return nil, fmt.Errorf("Index %v out of range", index)
+		}
+		items = append(items, nil)
+		copy(items[index+1:], items[index:])
+		items[index] = item
+		return items, nil
+	})
+}
+
+// Append adds item at the end of the collection, and returns the new version
+func (l *SnapshotList) Append(item interface{}) int64 {
+	version, _ := l.mutate(func(items []interface{}) ([]interface{}, error) {
+		return append(items, item), nil
+	})
+	return version
+}
+
+// Set replaces the item at index, and returns the new version
+func (l *SnapshotList) Set(index int, item interface{}) (int64, error) {
+	return l.mutate(func(items []interface{}) ([]interface{}, error) {
+		if index < 0 || index >= len(items) {
+			return nil, fmt.Errorf("Index %v out of range", index)
+		}
+		items[index] = item
+		return items, nil
+	})
+}
+
+// Remove removes the item at index, and returns the new version
+func (l *SnapshotList) Remove(index int) (int64, error) {
+	return l.mutate(func(items []interface{}) ([]interface{}, error) {
+		if index < 0 || index >= len(items) {
+			return nil, fmt.Errorf("Index %v out of range", index)
+		}
+		return append(items[:index], items[index+1:]...), nil
+	})
+}
+
+// mutate applies fn to a copy of the current items, so that snapshots are never modified
+func (l *SnapshotList) mutate(fn func(items []interface{}) ([]interface{}, error)) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	items, err := fn(append(make([]interface{}, 0, len(l.items)+1), l.items...))
+	if err != nil {
+		return 0, err
+	}
+	if l.retention > 0 {
+		l.snapshots[l.version] = l.items
+		l.versions = append(l.versions, l.version)
+		for len(l.versions) > l.retention {
+			delete(l.snapshots, l.versions[0])
+			l.versions = l.versions[1:]
+		}
+	}
+	l.version++
+	l.items = items
+	return l.version, nil
+}
+
+// snapshot returns the items of the given version, if retained, the lock
+// being held
+func (l *SnapshotList) snapshot(version int64) ([]interface{}, bool) {
+	if version == l.version {
+		return l.items, true
+	}
+	items, ok := l.snapshots[version]
+	return items, ok
+}
+
+// List implements ListSource. Pages are served from the snapshot recorded in
+// the `after` or `before` cursor, or from the current version if there is none.
+func (l *SnapshotList) List(args ListArguments, ctx context.Context) (*List, error) {
+	if err := ContextError(ctx); err != nil {
+		return nil, err
+	}
+	var version int64
+	fromCursor := false
+	offsetArgs := args
+	for _, cursor := range []*ListCursor{&offsetArgs.After, &offsetArgs.Before} {
+		if *cursor == "" {
+			continue
+		}
+		cursorVersion, offset, err := CursorToSnapshot(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		if fromCursor && cursorVersion != version {
+			return nil, errors.New("Cursors of different snapshots")
+		}
+		version, fromCursor = cursorVersion, true
+		*cursor = OffsetToCursor(offset)
+	}
+
+	// the current version is read along with its items, so that concurrent
+	// writes cannot make it stale
+	l.mu.RLock()
+	if !fromCursor {
+		version = l.version
+	}
+	items, ok := l.snapshot(version)
+	l.mu.RUnlock()
+	if !ok {
+		return nil, ErrStaleCursor
+	}
+	list := ListFromArray(items, offsetArgs)
+	if len(list.Items) > 0 {
+		list.PageInfo.StartCursor = SnapshotToCursor(version, GetOffsetWithDefault(list.PageInfo.StartCursor, 0))
+		list.PageInfo.EndCursor = SnapshotToCursor(version, GetOffsetWithDefault(list.PageInfo.EndCursor, 0))
+	}
+	return list, nil
+}
+
+// CursorFor implements CursorLocator, for the current version
+func (l *SnapshotList) CursorFor(object interface{}, ctx context.Context) (ListCursor, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i, item := range l.items {
+		if reflect.DeepEqual(item, object) {
+			return SnapshotToCursor(l.version, i), nil
+		}
+	}
+	return "", nil
+}
+
+// SnapshotItemCursor is the ItemCursorFn of lists returned by `SnapshotList`
+func SnapshotItemCursor(list *List, index int) ListCursor {
+	version, offset, err := CursorToSnapshot(list.PageInfo.StartCursor)
+	if err != nil {
+		return ""
+	}
+	return SnapshotToCursor(version, offset+index)
+}
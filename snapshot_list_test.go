@@ -0,0 +1,151 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotToCursor_RoundTrips(t *testing.T) {
+	version, offset, err := pagination.CursorToSnapshot(pagination.SnapshotToCursor(3, 42))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+	assert.Equal(t, 42, offset)
+
+	_, _, err = pagination.CursorToSnapshot(pagination.OffsetToCursor(42))
+	assert.EqualError(t, err, "Invalid cursor")
+}
+
+func TestSnapshotList_ServesPagesFromFirstPageSnapshot(t *testing.T) {
+	letters := pagination.NewSnapshotList(arrayListTestLetters, 1)
+
+	first, err := letters.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"A", "B"}, first.Items)
+	assert.Equal(t, pagination.SnapshotToCursor(1, 1), first.PageInfo.EndCursor)
+
+	// an insertion before the end cursor would shift offsets
+	_, err = letters.Insert(0, "Z")
+	assert.NoError(t, err)
+
+	next, err := letters.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(first.PageInfo.EndCursor),
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"C", "D"}, next.Items)
+	assert.Equal(t, pagination.SnapshotToCursor(1, 2), next.PageInfo.StartCursor)
+
+	// new paginations see the current version
+	current, err := letters.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}), context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"Z", "A"}, current.Items)
+	assert.Equal(t, pagination.SnapshotToCursor(2, 0), current.PageInfo.StartCursor)
+}
+
+func TestSnapshotList_ReturnsStaleCursorOnceSnapshotIsDropped(t *testing.T) {
+	letters := pagination.NewSnapshotList(arrayListTestLetters, 1)
+	first, err := letters.List(pagination.NewListArguments(map[string]interface{}{
+		"first": 2,
+	}), context.Background())
+	assert.NoError(t, err)
+
+	letters.Append("F")
+	_, err = letters.Remove(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), letters.Version())
+	assert.Equal(t, []interface{}{"B", "C", "D", "E", "F"}, letters.Items())
+
+	_, err = letters.List(pagination.NewListArguments(map[string]interface{}{
+		"after": string(first.PageInfo.EndCursor),
+	}), context.Background())
+	assert.Equal(t, pagination.ErrStaleCursor, err)
+}
+
+func TestSnapshotList_ReturnsErrorsForBadIndexes(t *testing.T) {
+	letters := pagination.NewSnapshotList(nil, 0)
+	_, err := letters.Set(0, "A")
+	assert.EqualError(t, err, "Index 0 out of range")
+	_, err = letters.Insert(1, "A")
+	assert.EqualError(t, err, "Index 1 out of range")
+	assert.Equal(t, int64(1), letters.Version())
+	assert.Equal(t, 0, letters.Len())
+}
+
+func TestSnapshotList_ReportsStaleCursorCode(t *testing.T) {
+	letters := pagination.NewSnapshotList(arrayListTestLetters, 0)
+	cursor, err := letters.CursorFor("B", context.Background())
+	assert.NoError(t, err)
+	_, err = letters.Set(1, "Y")
+	assert.NoError(t, err)
+
+	letterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Letter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+	letterListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name:     "Letter",
+		ItemType: letterType,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: letterListDef.ListType,
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return letters.List(pagination.NewListArguments(p.Args), p.Context)
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `query Letters($after: String) {
+          letters(after: $after) { items { value } }
+        }`,
+		VariableValues: map[string]interface{}{"after": string(cursor)},
+	})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Stale cursor", result.Errors[0].Message)
+		assert.Equal(t, []interface{}{"letters"}, result.Errors[0].Path)
+		assert.Equal(t, map[string]interface{}{"code": "STALE_CURSOR"}, result.Errors[0].Extensions)
+	}
+}
+
+func TestSnapshotList_ServesFirstPagesDuringWrites(t *testing.T) {
+	letters := pagination.NewSnapshotList([]interface{}{"A", "B", "C"}, 0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			letters.Set(0, "A")
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		_, err := letters.List(pagination.ListArguments{First: 2, Last: -1}, context.Background())
+		if !assert.NoError(t, err) {
+			break
+		}
+	}
+	<-done
+}
@@ -0,0 +1,80 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ErrTimeout is returned when the deadline of a request or the timeout of a
+// list policy is exceeded while loading data
+var ErrTimeout = &ListError{Code: "TIMEOUT", Message: "Timeout"}
+
+// ErrCanceled is returned when a request is canceled while loading data
+var ErrCanceled = &ListError{Code: "CANCELED", Message: "Canceled"}
+
+// ContextError returns `ErrTimeout` if the deadline of ctx is exceeded,
+// `ErrCanceled` if ctx is canceled, and nil otherwise (or if ctx is nil)
+func ContextError(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	switch err := ctx.Err(); {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	default:
+		return ErrCanceled
+	}
+}
+
+// isDone tells whether the done channel of a context is closed, without blocking
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Load loads the page of source described by the arguments of a list field,
+// applying the list policy and its timeout.
+// Items are filtered by the Authorize policy of the list before the page is
+// computed, see `AuthorizedSource`.
+// List sources interrupted by the timeout fail the field with their error,
+// e.g. `ErrTimeout` whose code GraphQL reports in the error extensions, the
+// items they loaded so far being dropped: use `LoadList` to get them.
+func (d *GraphQLListDefinitions) Load(source ListSource, p graphql.ResolveParams) (interface{}, error) {
+	list, err := d.LoadList(source, p)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LoadList is like `Load` but returns the page with the error of its source,
+// i.e. the partial page of sources interrupted by the timeout (nil if the
+// arguments or the authorization failed).
+func (d *GraphQLListDefinitions) LoadList(source ListSource, p graphql.ResolveParams) (*List, error) {
+	args, err := d.ListArguments(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if d.Policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Policy.Timeout)
+		defer cancel()
+	}
+
+	if source, err = d.AuthorizedSource(source, p.Info, ctx); err != nil {
+		return nil, err
+	}
+	return source.List(args, ctx)
+}
@@ -0,0 +1,169 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestContextError(t *testing.T) {
+	assert.Nil(t, pagination.ContextError(nil))
+	assert.Nil(t, pagination.ContextError(context.Background()))
+	assert.Equal(t, pagination.ErrCanceled, pagination.ContextError(canceledContext()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, pagination.ErrTimeout, pagination.ContextError(ctx))
+}
+
+func TestListSources_HonourContext(t *testing.T) {
+	ctx := canceledContext()
+	args := pagination.NewListArguments(nil)
+
+	_, err := pagination.ArrayListSource(arrayListTestLetters).List(args, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+	_, err = pagination.NewListIndex(arrayListTestLetters, nil).List(args, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+	_, err = pagination.NewSnapshotList(arrayListTestLetters, 0).List(args, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+	_, err = pagination.AuthorizedItems(arrayListTestLetters, func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (bool, error) {
+		return true, nil
+	}, graphql.ResolveInfo{}, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+
+	// walks return the partial page
+	list, err := newLetterCollection("A", "B", "C").List(args, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+	assert.Empty(t, list.Items)
+	assert.True(t, list.PageInfo.HasNextPage)
+
+	log := pagination.NewChangeLog()
+	log.Put("1", "A")
+	list, err = pagination.ChangeFeed(log, args, ctx)
+	assert.Equal(t, pagination.ErrCanceled, err)
+	assert.Empty(t, list.Items)
+	assert.True(t, list.PageInfo.HasNextPage)
+	assert.Equal(t, pagination.SeqToCursor(0), list.PageInfo.EndCursor)
+}
+
+func TestMergeListSources_TimesOutOnSlowSources(t *testing.T) {
+	merged := pagination.MergeListSources(lessInts,
+		pagination.MergeSource{Source: pagination.ArrayListSource{1, 2}},
+		pagination.MergeSource{Source: pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+			// ignores ctx
+			time.Sleep(time.Second)
+			return pagination.NewList(), nil
+		})},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := merged.List(pagination.NewListArguments(nil), ctx)
+	assert.Equal(t, pagination.ErrTimeout, err)
+}
+
+func TestLoad_ReturnsTimeoutErrorWhenListTimesOut(t *testing.T) {
+	letterListDef := pagination.ListDefinitions(pagination.ListConfig{
+		Name: "Letter",
+		ItemType: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Letter",
+			Fields: graphql.Fields{
+				"value": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
+			},
+		}),
+		Policy: pagination.ListPolicy{Timeout: 10 * time.Millisecond},
+	})
+	slowLetters := pagination.ListSourceFn(func(args pagination.ListArguments, ctx context.Context) (*pagination.List, error) {
+		list := pagination.ListFromArray(arrayListTestLetters[:2], args)
+		<-ctx.Done()
+		return list, pagination.ContextError(ctx)
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: letterListDef.ListType,
+					Args: pagination.ListArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return letterListDef.Load(slowLetters, p)
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ letters { items { value } } }`,
+	})
+	// partial pages are dropped
+	assert.EqualValues(t, map[string]interface{}{"letters": nil}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Timeout", result.Errors[0].Message)
+		assert.Equal(t, []interface{}{"letters"}, result.Errors[0].Path)
+		assert.Equal(t, map[string]interface{}{"code": "TIMEOUT"}, result.Errors[0].Extensions)
+	}
+
+	// unless loaded by LoadList
+	list, err := letterListDef.LoadList(slowLetters, graphql.ResolveParams{})
+	assert.Equal(t, pagination.ErrTimeout, err)
+	if assert.NotNil(t, list) {
+		assert.Equal(t, arrayListTestLetters[:2], list.Items)
+	}
+}
+
+func TestPluralIdentifyingRootField_TimesOutSlowInputs(t *testing.T) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"names": pagination.PluralIdentifyingRootField(pagination.PluralIdentifyingRootFieldConfig{
+					ArgName:     "ids",
+					InputType:   graphql.String,
+					OutputType:  graphql.String,
+					Concurrency: 2,
+					Timeout:     20 * time.Millisecond,
+					ResolveInput: func(input interface{}, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+						if input == "slow" {
+							<-ctx.Done()
+							return "too late", nil
+						}
+						return "name of " + input.(string), nil
+					},
+				}),
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ names(ids: ["a", "slow", "b"]) }`,
+	})
+	assert.EqualValues(t, map[string]interface{}{
+		"names": []interface{}{"name of a", nil, "name of b"},
+	}, result.Data)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Timeout", result.Errors[0].Message)
+		assert.Equal(t, []interface{}{"names", 1}, result.Errors[0].Path)
+	}
+}
@@ -0,0 +1,129 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// TypedMutationFn mutates and returns the payload, using Go types for the input and the payload
+type TypedMutationFn[In any, Out any] func(input In, info graphql.ResolveInfo, ctx context.Context) (Out, error)
+
+// TypedMutationConfig is the configuration of a typed mutation, see `MutationConfig`.
+// The input map is decoded into In using its `json` tags, so nullable input
+// fields should be declared as pointers to tell null apart from zero values.
+// The returned Out is turned into a payload map keyed by `json` tags, without
+// converting the field values, so that output field resolvers receive them as is.
+type TypedMutationConfig[In any, Out any] struct {
+	Name                string
+	InputFields         graphql.InputObjectConfigFieldMap
+	OutputFields        graphql.Fields
+	MutateAndGetPayload TypedMutationFn[In, Out]
+	IdempotencyStore    IdempotencyStore
+	Viewer              ViewerFn
+	UserErrors          bool
+	Middleware          []MutationMiddleware
+}
+
+// TypedMutation returns a GraphQLField for the mutation described by the
+// provided TypedMutationConfig, see `MutationWithClientMutationID`.
+func TypedMutation[In any, Out any](config TypedMutationConfig[In, Out]) *graphql.Field {
+	var mutateAndGetPayload MutationFn
+	if config.MutateAndGetPayload != nil {
+		mutateAndGetPayload = func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			var input In
+			if err := decodeMutationInput(inputMap, &input); err != nil {
+				return nil, err
+			}
+			out, err := config.MutateAndGetPayload(input, info, ctx)
+			if err != nil {
+				// payloads of failed mutations would only hold zero values
+				return nil, err
+			}
+			return encodeMutationPayload(out), nil
+		}
+	}
+	return MutationWithClientMutationID(MutationConfig{
+		Name:                config.Name,
+		InputFields:         config.InputFields,
+		OutputFields:        config.OutputFields,
+		MutateAndGetPayload: mutateAndGetPayload,
+		IdempotencyStore:    config.IdempotencyStore,
+		Viewer:              config.Viewer,
+		UserErrors:          config.UserErrors,
+		Middleware:          config.Middleware,
+	})
+}
+
+// decodeMutationInput decodes the input map of a mutation into target
+func decodeMutationInput(inputMap map[string]interface{}, target interface{}) error {
+	if targetMap, ok := target.(*map[string]interface{}); ok {
+		*targetMap = inputMap
+		return nil
+	}
+	b, err := json.Marshal(inputMap)
+	if err != nil {
+		return fmt.Errorf("Invalid input: %v", err)
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return fmt.Errorf("Invalid input: %v", err)
+	}
+	return nil
+}
+
+// encodeMutationPayload turns a payload struct into a payload map keyed by `json` tags
+func encodeMutationPayload(out interface{}) map[string]interface{} {
+	if payload, ok := out.(map[string]interface{}); ok {
+		return payload
+	}
+	value := reflect.ValueOf(out)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	payload := map[string]interface{}{}
+	encodeStructFields(value, payload)
+	return payload
+}
+
+func encodeStructFields(value reflect.Value, payload map[string]interface{}) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if tag == "-" {
+			continue
+		}
+		// promote the fields of untagged embedded structs, as encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := value.Field(i)
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				encodeStructFields(embedded, payload)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		payload[name] = value.Field(i).Interface()
+	}
+}
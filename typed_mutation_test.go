@@ -0,0 +1,178 @@
+package pagination_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	pagination "github.com/stratumn/graphql-pagination-go"
+)
+
+type typedMutationTestAddress struct {
+	City string `json:"city"`
+}
+
+type typedMutationTestInput struct {
+	Name    string                    `json:"name"`
+	Age     *int                      `json:"age"`
+	Address *typedMutationTestAddress `json:"address"`
+}
+
+type typedMutationTestPayload struct {
+	User   *user `json:"user"`
+	HasAge bool  `json:"hasAge"`
+	City   string
+}
+
+var typedMutationTestSchema graphql.Schema
+
+func init() {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	addressType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AddressInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"city": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+		},
+	})
+	mutation := pagination.TypedMutation(pagination.TypedMutationConfig[typedMutationTestInput, *typedMutationTestPayload]{
+		Name: "AddUser",
+		InputFields: graphql.InputObjectConfigFieldMap{
+			"name": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"age": &graphql.InputObjectFieldConfig{
+				Type: graphql.Int,
+			},
+			"address": &graphql.InputObjectFieldConfig{
+				Type: addressType,
+			},
+		},
+		OutputFields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+			},
+			"hasAge": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"City": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		MutateAndGetPayload: func(input typedMutationTestInput, info graphql.ResolveInfo, ctx context.Context) (*typedMutationTestPayload, error) {
+			if input.Name == "" {
+				// the payload of failed mutations is dropped
+				return &typedMutationTestPayload{HasAge: true}, pagination.NewUserError([]string{"name"}, "Name can't be blank", "BLANK")
+			}
+			payload := &typedMutationTestPayload{
+				User:   &user{ID: 3, Name: input.Name},
+				HasAge: input.Age != nil,
+			}
+			if input.Address != nil {
+				payload.City = input.Address.City
+			}
+			return payload, nil
+		},
+		UserErrors: true,
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"addUser": mutation,
+		},
+	})
+	typedMutationTestSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+}
+
+func TestTypedMutation_DecodesInputAndEncodesPayload(t *testing.T) {
+	query := `
+        mutation M {
+          withAge: addUser(input: {name: "Dan", age: 30, address: {city: "Paris"}, clientMutationId: "abc"}) {
+            user { id name }
+            hasAge
+            City
+            clientMutationId
+          }
+          withoutAge: addUser(input: {name: "Nick"}) {
+            hasAge
+            City
+            clientMutationId
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"withAge": map[string]interface{}{
+				"user": map[string]interface{}{
+					"id":   "3",
+					"name": "Dan",
+				},
+				"hasAge":           true,
+				"City":             "Paris",
+				"clientMutationId": "abc",
+			},
+			"withoutAge": map[string]interface{}{
+				"hasAge":           false,
+				"City":             "",
+				"clientMutationId": nil,
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        typedMutationTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestTypedMutation_ReturnsUserErrors(t *testing.T) {
+	query := `
+        mutation M {
+          addUser(input: {name: ""}) {
+            user { id }
+            hasAge
+            userErrors { field message code }
+          }
+        }
+      `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"addUser": map[string]interface{}{
+				"user":   nil,
+				"hasAge": nil,
+				"userErrors": []interface{}{
+					map[string]interface{}{
+						"field":   []interface{}{"name"},
+						"message": "Name can't be blank",
+						"code":    "BLANK",
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        typedMutationTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
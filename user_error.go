@@ -0,0 +1,80 @@
+package pagination
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// UserError is an expected mutation failure, meant to be displayed to the user.
+// When the mutation is configured with `UserErrors`, returning it from
+// mutateAndGetPayload fills the `userErrors` payload field instead of
+// producing a top-level GraphQL error.
+type UserError struct {
+	// Field is the path to the input field which caused the error, if any
+	Field   []string `json:"field"`
+	Message string   `json:"message"`
+	Code    string   `json:"code"`
+}
+
+// NewUserError is a UserError constructor
+func NewUserError(field []string, message string, code string) *UserError {
+	return &UserError{
+		Field:   field,
+		Message: message,
+		Code:    code,
+	}
+}
+
+// Error implements error
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+// UserErrors are several user errors returned at once
+type UserErrors []*UserError
+
+// Error implements error
+func (e UserErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, userError := range e {
+		messages[i] = userError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// AsUserErrors returns the user errors carried by err, if any
+func AsUserErrors(err error) (UserErrors, bool) {
+	var userErrors UserErrors
+	if errors.As(err, &userErrors) {
+		return userErrors, true
+	}
+	var userError *UserError
+	if errors.As(err, &userError) {
+		return UserErrors{userError}, true
+	}
+	return nil, false
+}
+
+/*
+The common user error type used by all mutation payloads.
+*/
+var userErrorType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "UserError",
+	Description: "An expected error, to be displayed to the user.",
+	Fields: graphql.Fields{
+		"field": &graphql.Field{
+			Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+			Description: "Path to the input field which caused the error.",
+		},
+		"message": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "The error message.",
+		},
+		"code": &graphql.Field{
+			Type:        graphql.String,
+			Description: "Machine readable error code.",
+		},
+	},
+})
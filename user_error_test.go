@@ -0,0 +1,33 @@
+package pagination_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pagination "github.com/stratumn/graphql-pagination-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserErrors_Error_JoinsMessages(t *testing.T) {
+	err := pagination.UserErrors{
+		pagination.NewUserError([]string{"name"}, "Name is reserved", "RESERVED"),
+		pagination.NewUserError(nil, "Try again later", ""),
+	}
+	assert.Equal(t, "Name is reserved; Try again later", err.Error())
+}
+
+func TestAsUserErrors_UnwrapsUserErrors(t *testing.T) {
+	userError := pagination.NewUserError([]string{"name"}, "Name can't be blank", "BLANK")
+
+	userErrors, ok := pagination.AsUserErrors(fmt.Errorf("validation: %w", userError))
+	assert.True(t, ok)
+	assert.Equal(t, pagination.UserErrors{userError}, userErrors)
+
+	userErrors, ok = pagination.AsUserErrors(fmt.Errorf("validation: %w", pagination.UserErrors{userError}))
+	assert.True(t, ok)
+	assert.Equal(t, pagination.UserErrors{userError}, userErrors)
+
+	_, ok = pagination.AsUserErrors(errors.New("boom"))
+	assert.False(t, ok)
+}